@@ -0,0 +1,481 @@
+package balogan
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// AlertFormat selects the payload of an alert webhook.
+type AlertFormat int
+
+const (
+	// AlertSlack posts {"text": "..."} as expected by Slack incoming webhooks
+	// and compatible services such as Mattermost and Rocket.Chat.
+	AlertSlack AlertFormat = iota
+	// AlertTeams posts a MessageCard as expected by Microsoft Teams incoming webhooks.
+	AlertTeams
+)
+
+// Default alert writer settings.
+const (
+	// DefaultAlertDedupWindow is the window in which identical alerts are collapsed.
+	DefaultAlertDedupWindow = time.Minute
+	// DefaultAlertRateLimit is the number of alerts per RateInterval sent to one destination.
+	DefaultAlertRateLimit = 20
+	// DefaultAlertRateInterval is the interval of the rate limit.
+	DefaultAlertRateInterval = time.Minute
+	// DefaultAlertTimeout limits every webhook request.
+	DefaultAlertTimeout = 10 * time.Second
+	// DefaultAlertQueueSize is the number of alerts waiting to be sent.
+	DefaultAlertQueueSize = 100
+
+	// DefaultAlertTemplate renders the level, logger, message and fields of an alert,
+	// and the number of occurrences when it stands for several records or repeats an earlier alert.
+	DefaultAlertTemplate = `{{.Level}}{{if .Logger}} [{{.Logger}}]{{end}}: {{.Message}}
+{{- range $key, $value := .Fields}}
+• {{$key}}: {{$value}}
+{{- end}}
+{{- if or .Repeated (gt .Count 1)}}
+{{.Count}} {{if .Repeated}}more {{end}}
+{{- if eq .Count 1}}occurrence at {{.LastSeen.Format "15:04:05"}}
+{{- else}}occurrences between {{.FirstSeen.Format "15:04:05"}} and {{.LastSeen.Format "15:04:05"}}
+{{- end}}
+{{- end}}`
+)
+
+// ErrAlertQueueFull is reported when an alert is dropped because the send queue is full.
+var ErrAlertQueueFull = errors.New("alert: queue full, alert dropped")
+
+// AlertDestination is a chat webhook receiving alerts.
+type AlertDestination struct {
+	// URL is the incoming webhook URL.
+	URL string
+	// Format is the payload format. AlertSlack is used by default.
+	Format AlertFormat
+}
+
+// AlertData is passed to the alert template.
+type AlertData struct {
+	Level   LogLevel
+	Message string
+	Logger  string
+	Caller  string
+	Fields  Fields
+	// Count is the number of records the alert stands for.
+	Count int
+	// Repeated reports whether the records repeat an alert which was already sent.
+	Repeated bool
+	// FirstSeen and LastSeen are the times of the first and last of these records.
+	FirstSeen time.Time
+	LastSeen  time.Time
+}
+
+// AlertConfig configures an AlertWriter.
+type AlertConfig struct {
+	// Destinations receive every alert.
+	Destinations []AlertDestination
+	// Levels are the alerted levels. ERROR, FATAL and PANIC are used when empty.
+	Levels []LogLevel
+
+	// Template is a text/template for the alert text, executed with AlertData.
+	// DefaultAlertTemplate is used when empty.
+	Template string
+
+	// DedupWindow collapses identical alerts: the first one is sent immediately,
+	// repeats within the window are counted and sent as one alert with
+	// "N occurrences" when the window ends. DefaultAlertDedupWindow is used when zero.
+	DedupWindow time.Duration
+	// DedupKey identifies identical alerts. Level, logger and message are used when nil.
+	DedupKey func(*Record) string
+
+	// RateLimit is the number of alerts sent to one destination per RateInterval.
+	// Alerts over the limit are not lost: they are counted and reported with the
+	// next alert which fits in the limit. DefaultAlertRateLimit and
+	// DefaultAlertRateInterval are used when zero, a negative RateLimit disables limiting.
+	RateLimit    int
+	RateInterval time.Duration
+
+	// Timeout limits every request. DefaultAlertTimeout is used when zero.
+	Timeout time.Duration
+	// Client sends the requests. http.DefaultClient is used when nil.
+	Client *http.Client
+	// ErrorHandler receives failed requests. When nil, they are returned by
+	// the next WriteRecord call and reach the logger's ErrorHandler.
+	ErrorHandler ErrorHandler
+}
+
+// AlertWriter posts alerts for ERROR and more severe records to chat webhooks
+// such as Slack and Microsoft Teams.
+//
+// Identical alerts within DedupWindow are collapsed into one alert reporting the
+// number of occurrences, and every destination is rate limited. Records suppressed
+// by deduplication or by the rate limit are counted, never silently lost.
+// Alerts are sent in the background, Close sends the remaining counts.
+type AlertWriter struct {
+	cfg          AlertConfig
+	template     *template.Template
+	retry        retryPolicy
+	destinations []*alertDestination
+
+	mu     sync.Mutex
+	errs   []error
+	closed bool
+
+	// queueMu guards closing the queue against concurrent enqueues.
+	queueMu sync.RWMutex
+	queue   chan alertJob
+	stop    chan struct{}
+	stopped chan struct{}
+	sent    chan struct{}
+}
+
+type alertDestination struct {
+	AlertDestination
+
+	windowStart time.Time
+	windowCount int
+	groups      map[string]*alertGroup
+}
+
+// alertGroup tracks identical alerts of one destination within a dedup window.
+type alertGroup struct {
+	windowStart  time.Time
+	record       *Record
+	pending      int
+	pendingSince time.Time
+	sent         bool
+	lastSeen     time.Time
+}
+
+type alertJob struct {
+	destination AlertDestination
+	data        AlertData
+}
+
+// NewAlertWriter creates a new AlertWriter.
+func NewAlertWriter(cfg *AlertConfig) (*AlertWriter, error) {
+	if cfg == nil || len(cfg.Destinations) == 0 {
+		return nil, errors.New("alert: at least one destination is required")
+	}
+
+	c := *cfg
+	if len(c.Levels) == 0 {
+		c.Levels = []LogLevel{ErrorLevel, FatalLevel, PanicLevel}
+	}
+	if c.Template == "" {
+		c.Template = DefaultAlertTemplate
+	}
+	if c.DedupWindow <= 0 {
+		c.DedupWindow = DefaultAlertDedupWindow
+	}
+	if c.DedupKey == nil {
+		c.DedupKey = defaultAlertKey
+	}
+	if c.RateLimit == 0 {
+		c.RateLimit = DefaultAlertRateLimit
+	}
+	if c.RateInterval <= 0 {
+		c.RateInterval = DefaultAlertRateInterval
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = DefaultAlertTimeout
+	}
+	if c.Client == nil {
+		c.Client = http.DefaultClient
+	}
+
+	tmpl, err := template.New("alert").Parse(c.Template)
+	if err != nil {
+		return nil, fmt.Errorf("alert: %w", err)
+	}
+
+	w := &AlertWriter{
+		cfg:      c,
+		template: tmpl,
+		retry:    retryPolicy{maxRetries: 2, backoff: time.Second, jitter: true},
+		queue:    make(chan alertJob, DefaultAlertQueueSize),
+		stop:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+		sent:     make(chan struct{}),
+	}
+	for _, d := range c.Destinations {
+		if d.URL == "" {
+			return nil, errors.New("alert: destination url is required")
+		}
+		w.destinations = append(w.destinations, &alertDestination{
+			AlertDestination: d,
+			groups:           make(map[string]*alertGroup),
+		})
+	}
+
+	go w.loop()
+	go w.sender(w.queue)
+	return w, nil
+}
+
+// Write sends bytes as the message of an ERROR level alert.
+func (w *AlertWriter) Write(bytes []byte) (int, error) {
+	record := &Record{Time: time.Now(), Level: ErrorLevel, Message: string(bytes)}
+	if err := w.WriteRecord(record, bytes); err != nil {
+		return 0, err
+	}
+	return len(bytes), nil
+}
+
+// WriteRecord alerts the record when its level is one of Levels.
+// It returns the errors of earlier failed requests when there is no ErrorHandler.
+func (w *AlertWriter) WriteRecord(record *Record, line []byte) error {
+	if !slices.Contains(w.cfg.Levels, record.Level) {
+		return nil
+	}
+
+	key := w.cfg.DedupKey(record)
+	now := time.Now()
+	// Alerts are rendered later by the sender, after hooks may have modified the record.
+	record = cloneRecord(record)
+
+	var jobs []alertJob
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return os.ErrClosed
+	}
+	for _, d := range w.destinations {
+		g := d.groups[key]
+		if g == nil {
+			g = &alertGroup{windowStart: now, record: record, lastSeen: now}
+			d.groups[key] = g
+			if w.allow(d, now) {
+				g.sent = true
+				jobs = append(jobs, alertJob{destination: d.AlertDestination, data: newAlertData(record, 1, now, now, false)})
+			} else {
+				g.pending, g.pendingSince = 1, now
+			}
+			continue
+		}
+
+		if g.pending == 0 {
+			g.pendingSince = now
+		}
+		g.pending++
+		g.record = record
+		g.lastSeen = now
+	}
+	err := errors.Join(w.errs...)
+	w.errs = nil
+	w.mu.Unlock()
+
+	w.enqueue(jobs)
+	return err
+}
+
+// Close sends the counts of suppressed alerts, waits for queued alerts to be sent
+// and stops the writer.
+func (w *AlertWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return os.ErrClosed
+	}
+	w.closed = true
+	w.mu.Unlock()
+
+	close(w.stop)
+	<-w.stopped
+
+	w.enqueue(w.flushGroups(time.Now(), true))
+	w.queueMu.Lock()
+	close(w.queue)
+	w.queue = nil
+	w.queueMu.Unlock()
+	<-w.sent
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	err := errors.Join(w.errs...)
+	w.errs = nil
+	return err
+}
+
+func (w *AlertWriter) loop() {
+	defer close(w.stopped)
+
+	interval := max(min(w.cfg.DedupWindow/4, time.Second), time.Millisecond)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stop:
+			return
+		case now := <-ticker.C:
+			w.enqueue(w.flushGroups(now, false))
+		}
+	}
+}
+
+// flushGroups returns alerts with the counts of groups whose dedup window has
+// ended and forgets groups without suppressed alerts. With force, all counts are
+// returned regardless of windows and rate limits.
+func (w *AlertWriter) flushGroups(now time.Time, force bool) []alertJob {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var jobs []alertJob
+	for _, d := range w.destinations {
+		for key, g := range d.groups {
+			if !force && now.Sub(g.windowStart) < w.cfg.DedupWindow {
+				continue
+			}
+			if g.pending == 0 {
+				delete(d.groups, key)
+				continue
+			}
+			if !force && !w.allow(d, now) {
+				continue
+			}
+
+			jobs = append(jobs, alertJob{
+				destination: d.AlertDestination,
+				data:        newAlertData(g.record, g.pending, g.pendingSince, g.lastSeen, g.sent),
+			})
+			g.sent = true
+			g.pending = 0
+			g.windowStart = now
+		}
+	}
+	return jobs
+}
+
+// allow reports whether another alert may be sent to d. The caller must hold w.mu.
+func (w *AlertWriter) allow(d *alertDestination, now time.Time) bool {
+	if w.cfg.RateLimit < 0 {
+		return true
+	}
+	if now.Sub(d.windowStart) >= w.cfg.RateInterval {
+		d.windowStart = now
+		d.windowCount = 0
+	}
+	if d.windowCount < w.cfg.RateLimit {
+		d.windowCount++
+		return true
+	}
+	return false
+}
+
+// enqueue queues alerts for the sender. Alerts which do not fit in the queue are dropped.
+func (w *AlertWriter) enqueue(jobs []alertJob) {
+	w.queueMu.RLock()
+	defer w.queueMu.RUnlock()
+
+	if w.queue == nil {
+		return
+	}
+	for _, job := range jobs {
+		select {
+		case w.queue <- job:
+		default:
+			w.handleError(ErrAlertQueueFull)
+		}
+	}
+}
+
+func (w *AlertWriter) sender(queue <-chan alertJob) {
+	defer close(w.sent)
+
+	for job := range queue {
+		if err := w.send(job); err != nil {
+			w.handleError(err)
+		}
+	}
+}
+
+func (w *AlertWriter) send(job alertJob) error {
+	var text strings.Builder
+	if err := w.template.Execute(&text, job.data); err != nil {
+		return fmt.Errorf("alert: %w", err)
+	}
+
+	var payload interface{}
+	switch job.destination.Format {
+	case AlertTeams:
+		payload = map[string]string{
+			"@type":      "MessageCard",
+			"@context":   "https://schema.org/extensions",
+			"summary":    job.data.Message,
+			"themeColor": alertColor(job.data.Level),
+			"text":       text.String(),
+		}
+	default:
+		payload = map[string]string{"text": text.String()}
+	}
+
+	var body bytes.Buffer
+	if err := json.NewEncoder(&body).Encode(payload); err != nil {
+		return fmt.Errorf("alert: %w", err)
+	}
+
+	req := httpRequest{
+		method:  http.MethodPost,
+		url:     job.destination.URL,
+		headers: map[string]string{"Content-Type": "application/json"},
+		body:    body.Bytes(),
+	}
+	err := w.retry.do(context.Background(), func() error {
+		_, err := req.send(context.Background(), w.cfg.Client, w.cfg.Timeout)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("alert: %w", err)
+	}
+	return nil
+}
+
+// handleError passes err to the error handler or keeps it for the next write.
+func (w *AlertWriter) handleError(err error) {
+	if w.cfg.ErrorHandler != nil {
+		w.cfg.ErrorHandler.Handle(err)
+		return
+	}
+	w.mu.Lock()
+	w.errs = append(w.errs, err)
+	w.mu.Unlock()
+}
+
+func newAlertData(record *Record, count int, first, last time.Time, repeated bool) AlertData {
+	return AlertData{
+		Level:     record.Level,
+		Message:   record.Message,
+		Logger:    record.Logger,
+		Caller:    record.Caller,
+		Fields:    record.Fields,
+		Count:     count,
+		Repeated:  repeated,
+		FirstSeen: first,
+		LastSeen:  last,
+	}
+}
+
+func defaultAlertKey(record *Record) string {
+	return record.Level.String() + "\x00" + record.Logger + "\x00" + record.Message
+}
+
+func alertColor(level LogLevel) string {
+	if level >= FatalLevel {
+		return "8B0000"
+	}
+	if level == ErrorLevel {
+		return "E01E5A"
+	}
+	return "ECB22E"
+}
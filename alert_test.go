@@ -0,0 +1,222 @@
+package balogan
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func alertTexts(t *testing.T, server *recordingServer[string]) []string {
+	var texts []string
+	for _, req := range server.captured() {
+		var payload map[string]string
+		if err := json.Unmarshal([]byte(req.body), &payload); err != nil {
+			t.Fatalf("Invalid alert payload %q: %v", req.body, err)
+		}
+		texts = append(texts, payload["text"])
+	}
+	return texts
+}
+
+func newTestAlertWriter(t *testing.T, cfg AlertConfig) *AlertWriter {
+	w, err := NewAlertWriter(&cfg)
+	if err != nil {
+		t.Fatalf("NewAlertWriter failed: %v", err)
+	}
+	return w
+}
+
+func TestAlertWriter_Levels(t *testing.T) {
+	server := newRecordingServer(t, decodeText)
+	writer := newTestAlertWriter(t, AlertConfig{Destinations: []AlertDestination{{URL: server.URL}}})
+
+	logger := New(DebugLevel, writer).WithName("billing")
+	logger.Warning("not alerted")
+	logger.WithFields(Fields{"order": 42, "user": "john"}).Error("payment failed")
+
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	texts := alertTexts(t, server)
+	if len(texts) != 1 {
+		t.Fatalf("Expected only the ERROR record to be alerted, got %v", texts)
+	}
+	expected := "ERROR [billing]: payment failed\n• order: 42\n• user: john"
+	if texts[0] != expected {
+		t.Errorf("Expected %q, got %q", expected, texts[0])
+	}
+}
+
+func TestAlertWriter_Deduplication(t *testing.T) {
+	server := newRecordingServer(t, decodeText)
+	writer := newTestAlertWriter(t, AlertConfig{
+		Destinations: []AlertDestination{{URL: server.URL}},
+		DedupWindow:  50 * time.Millisecond,
+		Template:     "{{.Message}} x{{.Count}}",
+	})
+
+	logger := New(InfoLevel, writer)
+	for range 5 {
+		logger.Error("database down")
+	}
+	logger.Error("disk full")
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(server.captured()) < 3 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	texts := strings.Join(alertTexts(t, server), "|")
+	for _, expected := range []string{"database down x1", "disk full x1", "database down x4"} {
+		if !strings.Contains(texts, expected) {
+			t.Errorf("Expected alert %q, got %q", expected, texts)
+		}
+	}
+	if len(server.captured()) != 3 {
+		t.Errorf("Expected 3 alerts, got %q", texts)
+	}
+}
+
+func TestAlertWriter_SingleRepeatShowsCount(t *testing.T) {
+	server := newRecordingServer(t, decodeText)
+	writer := newTestAlertWriter(t, AlertConfig{
+		Destinations: []AlertDestination{{URL: server.URL}},
+		DedupWindow:  time.Hour,
+	})
+
+	logger := New(InfoLevel, writer)
+	logger.Error("database down")
+	logger.Error("database down")
+
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	texts := alertTexts(t, server)
+	if len(texts) != 2 {
+		t.Fatalf("Expected the alert and its repeat, got %v", texts)
+	}
+	if strings.Contains(texts[0], "occurrence") {
+		t.Errorf("Expected the first alert without a count, got %q", texts[0])
+	}
+	if !strings.HasPrefix(texts[1], "ERROR: database down\n1 more occurrence at ") {
+		t.Errorf("Expected the repeat to show its count, got %q", texts[1])
+	}
+}
+
+func TestAlertWriter_RateLimitKeepsCount(t *testing.T) {
+	server := newRecordingServer(t, decodeText)
+	writer := newTestAlertWriter(t, AlertConfig{
+		Destinations: []AlertDestination{{URL: server.URL}},
+		DedupWindow:  time.Hour,
+		RateLimit:    1,
+		RateInterval: time.Hour,
+		Template:     "{{.Message}} x{{.Count}}",
+	})
+
+	logger := New(InfoLevel, writer)
+	logger.Error("first")
+	logger.Error("second")
+	logger.Error("second")
+
+	time.Sleep(50 * time.Millisecond)
+	if got := len(server.captured()); got != 1 {
+		t.Fatalf("Expected rate limit to allow 1 alert, got %d", got)
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	texts := alertTexts(t, server)
+	if len(texts) != 2 || texts[1] != "second x2" {
+		t.Errorf("Expected suppressed alerts to be reported with their count on Close, got %v", texts)
+	}
+}
+
+func TestAlertWriter_KeepsRecordSnapshot(t *testing.T) {
+	server := newRecordingServer(t, decodeText)
+	writer := newTestAlertWriter(t, AlertConfig{
+		Destinations: []AlertDestination{{URL: server.URL}},
+		DedupWindow:  time.Millisecond,
+		Template:     "{{.Message}} {{.Fields.stage}}",
+	})
+
+	// The hook modifies the shared record while alerts are being rendered.
+	logger := New(InfoLevel, writer).WithHook(AfterWrite, NewHook(func(record *Record) error {
+		record.Fields["stage"] = "after"
+		return nil
+	})).WithField("stage", "write")
+
+	for range 20 {
+		logger.Error("snapshot")
+		time.Sleep(time.Millisecond)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	for _, text := range alertTexts(t, server) {
+		if text != "snapshot write" {
+			t.Fatalf("Alerts should be rendered as written, got %q", text)
+		}
+	}
+}
+
+func TestAlertWriter_Teams(t *testing.T) {
+	server := newRecordingServer(t, decodeText)
+	writer := newTestAlertWriter(t, AlertConfig{
+		Destinations: []AlertDestination{{URL: server.URL, Format: AlertTeams}},
+	})
+
+	New(InfoLevel, writer).Error("teams alert")
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	var card map[string]string
+	if err := json.Unmarshal([]byte(server.captured()[0].body), &card); err != nil {
+		t.Fatalf("Invalid card: %v", err)
+	}
+	if card["@type"] != "MessageCard" || card["summary"] != "teams alert" || !strings.Contains(card["text"], "teams alert") {
+		t.Errorf("Unexpected card %v", card)
+	}
+}
+
+func TestAlertWriter_Errors(t *testing.T) {
+	server := newRecordingServer(t, decodeText)
+	server.failures = 1
+	server.status = http.StatusForbidden
+
+	writer := newTestAlertWriter(t, AlertConfig{Destinations: []AlertDestination{{URL: server.URL}}})
+	New(InfoLevel, writer).Error("forbidden")
+
+	err := writer.Close()
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected failed alert to be reported, got %v", err)
+	}
+
+	if _, err := writer.Write([]byte("late")); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Expected os.ErrClosed, got %v", err)
+	}
+}
+
+func TestNewAlertWriter_InvalidConfig(t *testing.T) {
+	if _, err := NewAlertWriter(&AlertConfig{}); err == nil {
+		t.Error("Expected error without destinations")
+	}
+	if _, err := NewAlertWriter(&AlertConfig{
+		Destinations: []AlertDestination{{URL: "http://localhost"}},
+		Template:     "{{.Message",
+	}); err == nil {
+		t.Error("Expected error for invalid template")
+	}
+}
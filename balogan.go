@@ -4,8 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"strings"
 	"sync"
+	"time"
 )
 
 var DefaultWriter = NewStdOutLogWriter()
@@ -14,6 +16,13 @@ type ErrorHandler interface {
 	Handle(err error)
 }
 
+// ErrorHandlerFunc adapts an ordinary function to the ErrorHandler interface.
+type ErrorHandlerFunc func(err error)
+
+func (f ErrorHandlerFunc) Handle(err error) {
+	f(err)
+}
+
 type DefaultErrorHandler struct{}
 
 func (h *DefaultErrorHandler) Handle(error) {}
@@ -37,6 +46,34 @@ type Logger struct {
 	conditions        []Condition
 	levelConditions   []LevelCondition
 	contextConditions []ContextCondition
+
+	// Fatal exit handling
+	exitFunc     ExitFunc
+	exitCode     int
+	exitTimeout  time.Duration
+	exitHandlers []ExitHandler
+
+	// Panic recovery
+	repanic bool
+
+	// Hooks
+	beforeHooks []Hook
+	afterHooks  []Hook
+
+	// Record processing
+	processors []Processor
+	redactor   *Redactor
+
+	// Output encoding
+	encoder      Encoder
+	name         string
+	reportCaller bool
+
+	// Context bound with ForContext
+	ctx context.Context
+
+	// Handling of messages with newlines
+	multiline MultilinePolicy
 }
 
 // The simpliest way to create new Balogan Logger instance.
@@ -63,6 +100,10 @@ func New(level LogLevel, writer LogWriter, prefixes ...PrefixBuilderFunc) *Logge
 		conditions:        []Condition{},
 		levelConditions:   []LevelCondition{},
 		contextConditions: []ContextCondition{},
+		exitFunc:          os.Exit,
+		exitCode:          DefaultExitCode,
+		exitTimeout:       DefaultExitTimeout,
+		exitHandlers:      []ExitHandler{},
 	}
 }
 
@@ -73,9 +114,32 @@ type BaloganConfig struct {
 
 	Concurrency bool
 
+	// ErrorHandler receives writer and hook errors.
+	// DefaultErrorHandler is used when nil.
+	ErrorHandler ErrorHandler
+
 	// Structured logging configuration
 	Fields          Fields
 	FieldsFormatter FieldsFormatter
+
+	// Encoder replaces the default text line with a whole-record encoding.
+	Encoder Encoder
+	// Name is the logger name reported by encoders.
+	Name string
+	// ReportCaller adds the calling file and line to every record.
+	ReportCaller bool
+	// Multiline selects how messages containing newlines are written.
+	Multiline MultilinePolicy
+
+	// Fatal exit configuration.
+	// Zero values fall back to os.Exit, DefaultExitCode and DefaultExitTimeout.
+	ExitFunc     ExitFunc
+	ExitCode     int
+	ExitTimeout  time.Duration
+	ExitHandlers []ExitHandler
+
+	// Repanic makes Recover re-panic after logging the recovered value.
+	Repanic bool
 }
 
 func NewFromConfig(cfg *BaloganConfig) *Logger {
@@ -92,6 +156,10 @@ func NewFromConfig(cfg *BaloganConfig) *Logger {
 			conditions:        []Condition{},
 			levelConditions:   []LevelCondition{},
 			contextConditions: []ContextCondition{},
+			exitFunc:          os.Exit,
+			exitCode:          DefaultExitCode,
+			exitTimeout:       DefaultExitTimeout,
+			exitHandlers:      []ExitHandler{},
 		}
 	}
 
@@ -105,17 +173,49 @@ func NewFromConfig(cfg *BaloganConfig) *Logger {
 		fieldsFormatter = DefaultFieldsFormatter
 	}
 
+	exitFunc := cfg.ExitFunc
+	if exitFunc == nil {
+		exitFunc = os.Exit
+	}
+
+	exitCode := cfg.ExitCode
+	if exitCode == 0 {
+		exitCode = DefaultExitCode
+	}
+
+	exitTimeout := cfg.ExitTimeout
+	if exitTimeout <= 0 {
+		exitTimeout = DefaultExitTimeout
+	}
+
+	exitHandlers := make([]ExitHandler, len(cfg.ExitHandlers))
+	copy(exitHandlers, cfg.ExitHandlers)
+
+	var errorHandler ErrorHandler = &DefaultErrorHandler{}
+	if cfg.ErrorHandler != nil {
+		errorHandler = cfg.ErrorHandler
+	}
+
 	return &Logger{
 		level:             cfg.Level,
 		writers:           cfg.Writers,
 		prefixes:          cfg.Prefixes,
-		errorHandler:      &DefaultErrorHandler{},
+		errorHandler:      errorHandler,
 		concurrency:       cfg.Concurrency,
 		fields:            fields,
 		fieldsFormatter:   fieldsFormatter,
 		conditions:        []Condition{},
 		levelConditions:   []LevelCondition{},
 		contextConditions: []ContextCondition{},
+		exitFunc:          exitFunc,
+		exitCode:          exitCode,
+		exitTimeout:       exitTimeout,
+		exitHandlers:      exitHandlers,
+		repanic:           cfg.Repanic,
+		encoder:           cfg.Encoder,
+		name:              cfg.Name,
+		reportCaller:      cfg.ReportCaller,
+		multiline:         cfg.Multiline,
 	}
 }
 
@@ -137,6 +237,20 @@ func (l *Logger) WithTemporaryPrefix(builder ...PrefixBuilderFunc) *Logger {
 		conditions:        l.conditions,
 		levelConditions:   l.levelConditions,
 		contextConditions: l.contextConditions,
+		exitFunc:          l.exitFunc,
+		exitCode:          l.exitCode,
+		exitTimeout:       l.exitTimeout,
+		exitHandlers:      l.exitHandlers,
+		repanic:           l.repanic,
+		beforeHooks:       l.beforeHooks,
+		afterHooks:        l.afterHooks,
+		processors:        l.processors,
+		redactor:          l.redactor,
+		encoder:           l.encoder,
+		name:              l.name,
+		reportCaller:      l.reportCaller,
+		ctx:               l.ctx,
+		multiline:         l.multiline,
 	}
 }
 
@@ -153,9 +267,7 @@ func (l *Logger) Logf(level LogLevel, format string, args ...interface{}) {
 		return
 	}
 
-	message := fmt.Sprintf(format, args...)
-	fullMessage := l.buildMessage(level, message)
-	l.write(fullMessage)
+	l.log(level, fmt.Sprintf(format, args...))
 }
 
 // Log logs a message at the specified level.
@@ -170,9 +282,7 @@ func (l *Logger) Log(level LogLevel, args ...interface{}) {
 		return
 	}
 
-	message := strings.TrimSpace(fmt.Sprintln(args...))
-	fullMessage := l.buildMessage(level, message)
-	l.write(fullMessage)
+	l.log(level, strings.TrimSpace(fmt.Sprintln(args...)))
 }
 
 // Debug logs a message at the DEBUG level.
@@ -280,18 +390,20 @@ func (l *Logger) Tracef(format string, args ...interface{}) {
 }
 
 // Fatal logs a message at the FATAL level and then exits the program.
-// It calls the general Log method with the FATAL level, then calls os.Exit(1).
+// It calls the general Log method with the FATAL level, then calls Exit
+// with the configured exit code (1 by default).
 //
 // Parameters:
 //
 //	args: The arguments to be logged.
 func (l *Logger) Fatal(args ...interface{}) {
 	l.Log(FatalLevel, args...)
-	FatalLevel.Exit()
+	l.Exit(l.exitCode)
 }
 
 // Fatalf logs a formatted message at the FATAL level and then exits the program.
-// It calls the general Logf method with the FATAL level, then calls os.Exit(1).
+// It calls the general Logf method with the FATAL level, then calls Exit
+// with the configured exit code (1 by default).
 //
 // Parameters:
 //
@@ -299,7 +411,7 @@ func (l *Logger) Fatal(args ...interface{}) {
 //	args: The arguments for the format string.
 func (l *Logger) Fatalf(format string, args ...interface{}) {
 	l.Logf(FatalLevel, format, args...)
-	FatalLevel.Exit()
+	l.Exit(l.exitCode)
 }
 
 // Panic logs a message at the PANIC level and then panics.
@@ -383,6 +495,20 @@ func (l *Logger) WithField(key string, value interface{}) *Logger {
 		conditions:        l.conditions,
 		levelConditions:   l.levelConditions,
 		contextConditions: l.contextConditions,
+		exitFunc:          l.exitFunc,
+		exitCode:          l.exitCode,
+		exitTimeout:       l.exitTimeout,
+		exitHandlers:      l.exitHandlers,
+		repanic:           l.repanic,
+		beforeHooks:       l.beforeHooks,
+		afterHooks:        l.afterHooks,
+		processors:        l.processors,
+		redactor:          l.redactor,
+		encoder:           l.encoder,
+		name:              l.name,
+		reportCaller:      l.reportCaller,
+		ctx:               l.ctx,
+		multiline:         l.multiline,
 	}
 }
 
@@ -404,6 +530,20 @@ func (l *Logger) WithFields(fields Fields) *Logger {
 		conditions:        l.conditions,
 		levelConditions:   l.levelConditions,
 		contextConditions: l.contextConditions,
+		exitFunc:          l.exitFunc,
+		exitCode:          l.exitCode,
+		exitTimeout:       l.exitTimeout,
+		exitHandlers:      l.exitHandlers,
+		repanic:           l.repanic,
+		beforeHooks:       l.beforeHooks,
+		afterHooks:        l.afterHooks,
+		processors:        l.processors,
+		redactor:          l.redactor,
+		encoder:           l.encoder,
+		name:              l.name,
+		reportCaller:      l.reportCaller,
+		ctx:               l.ctx,
+		multiline:         l.multiline,
 	}
 }
 
@@ -425,6 +565,20 @@ func (l *Logger) WithFieldsFormatter(formatter FieldsFormatter) *Logger {
 		conditions:        l.conditions,
 		levelConditions:   l.levelConditions,
 		contextConditions: l.contextConditions,
+		exitFunc:          l.exitFunc,
+		exitCode:          l.exitCode,
+		exitTimeout:       l.exitTimeout,
+		exitHandlers:      l.exitHandlers,
+		repanic:           l.repanic,
+		beforeHooks:       l.beforeHooks,
+		afterHooks:        l.afterHooks,
+		processors:        l.processors,
+		redactor:          l.redactor,
+		encoder:           l.encoder,
+		name:              l.name,
+		reportCaller:      l.reportCaller,
+		ctx:               l.ctx,
+		multiline:         l.multiline,
 	}
 }
 
@@ -483,38 +637,96 @@ func (l *Logger) GetFields() Fields {
 	return l.fields.Copy()
 }
 
-func (l *Logger) buildPrefixStr(args ...any) string {
+func (l *Logger) buildPrefixes(args ...any) []string {
 	var prefixes []string
 	for _, f := range l.prefixes {
 		prefixes = append(prefixes, f(args))
 	}
-	return strings.Join(prefixes, " ")
+	return prefixes
+}
+
+// newRecord captures the current state of the logger into a Record.
+// Fields are copied so hooks may modify them without affecting the logger.
+func (l *Logger) newRecord(level LogLevel, message string) *Record {
+	record := &Record{
+		Time:     time.Now(),
+		Level:    level,
+		Message:  message,
+		Fields:   l.fields.Copy(),
+		Prefixes: l.buildPrefixes(),
+		Logger:   l.name,
+		Context:  l.ctx,
+	}
+
+	if l.reportCaller {
+		record.Caller = callerLocation()
+	}
+
+	return record
 }
 
-func (l *Logger) buildMessage(level LogLevel, message string) string {
-	parts := []string{level.String()}
+// log passes an already accepted message through hooks and writers.
+func (l *Logger) log(level LogLevel, message string) {
+	record := l.newRecord(level, message)
+	if !l.process(record) {
+		return
+	}
+	if l.redactor != nil {
+		l.redactor.Redact(record)
+	}
 
-	prefixStr := l.buildPrefixStr()
+	l.fireHooks(l.beforeHooks, record)
+	if l.multiline == MultilineSplit && strings.ContainsAny(record.Message, "\r\n") {
+		for _, part := range splitRecord(record) {
+			l.write(part, l.multiline.apply(l.format(part)))
+		}
+	} else {
+		l.write(record, l.multiline.apply(l.format(record)))
+	}
+	l.fireHooks(l.afterHooks, record)
+}
+
+// format renders the record with the logger's encoder,
+// falling back to the plain text line when no encoder is set.
+func (l *Logger) format(record *Record) string {
+	if l.encoder != nil {
+		return l.encoder.Encode(record)
+	}
+
+	return l.buildMessage(record)
+}
+
+func (l *Logger) buildMessage(record *Record) string {
+	return textLine(record, l.fieldsFormatter)
+}
+
+// textLine renders the default "LEVEL prefixes fields message" line.
+func textLine(record *Record, formatter FieldsFormatter) string {
+	parts := []string{record.Level.String()}
+
+	prefixStr := strings.Join(record.Prefixes, " ")
 	if prefixStr != "" {
 		parts = append(parts, prefixStr)
 	}
 
-	if len(l.fields) > 0 {
-		fieldsStr := l.fieldsFormatter.Format(l.fields)
+	if len(record.Fields) > 0 {
+		fieldsStr := formatter.Format(record.Fields)
 		if fieldsStr != "" {
 			parts = append(parts, fieldsStr)
 		}
 	}
 
-	parts = append(parts, message)
+	parts = append(parts, record.Message)
 
 	return strings.Join(parts, " ")
 }
 
-func (l *Logger) write(message string) {
+func (l *Logger) write(record *Record, message string) {
 	l.mutex.Lock()
 	defer l.mutex.Unlock()
 
+	line := []byte(message)
+
 	if l.concurrency {
 		var wg sync.WaitGroup
 		var errsMu sync.Mutex
@@ -523,7 +735,7 @@ func (l *Logger) write(message string) {
 			wg.Add(1)
 			go func(w LogWriter) {
 				defer wg.Done()
-				if _, err := w.Write([]byte(message)); err != nil {
+				if err := writeRecord(w, record, line, l.multiline); err != nil {
 					errsMu.Lock()
 					errs = append(errs, err)
 					errsMu.Unlock()
@@ -538,7 +750,7 @@ func (l *Logger) write(message string) {
 	} else {
 		var errs []error
 		for _, writer := range l.writers {
-			_, err := writer.Write([]byte(message))
+			err := writeRecord(writer, record, line, l.multiline)
 			if err != nil {
 				errs = append(errs, err)
 			}
@@ -575,6 +787,20 @@ func (l *Logger) When(condition Condition) *Logger {
 		conditions:        conditions,
 		levelConditions:   l.levelConditions,
 		contextConditions: l.contextConditions,
+		exitFunc:          l.exitFunc,
+		exitCode:          l.exitCode,
+		exitTimeout:       l.exitTimeout,
+		exitHandlers:      l.exitHandlers,
+		repanic:           l.repanic,
+		beforeHooks:       l.beforeHooks,
+		afterHooks:        l.afterHooks,
+		processors:        l.processors,
+		redactor:          l.redactor,
+		encoder:           l.encoder,
+		name:              l.name,
+		reportCaller:      l.reportCaller,
+		ctx:               l.ctx,
+		multiline:         l.multiline,
 	}
 }
 
@@ -604,6 +830,20 @@ func (l *Logger) WithLevelCondition(condition LevelCondition) *Logger {
 		conditions:        l.conditions,
 		levelConditions:   levelConditions,
 		contextConditions: l.contextConditions,
+		exitFunc:          l.exitFunc,
+		exitCode:          l.exitCode,
+		exitTimeout:       l.exitTimeout,
+		exitHandlers:      l.exitHandlers,
+		repanic:           l.repanic,
+		beforeHooks:       l.beforeHooks,
+		afterHooks:        l.afterHooks,
+		processors:        l.processors,
+		redactor:          l.redactor,
+		encoder:           l.encoder,
+		name:              l.name,
+		reportCaller:      l.reportCaller,
+		ctx:               l.ctx,
+		multiline:         l.multiline,
 	}
 }
 
@@ -633,6 +873,20 @@ func (l *Logger) WithContextCondition(condition ContextCondition) *Logger {
 		conditions:        l.conditions,
 		levelConditions:   l.levelConditions,
 		contextConditions: contextConditions,
+		exitFunc:          l.exitFunc,
+		exitCode:          l.exitCode,
+		exitTimeout:       l.exitTimeout,
+		exitHandlers:      l.exitHandlers,
+		repanic:           l.repanic,
+		beforeHooks:       l.beforeHooks,
+		afterHooks:        l.afterHooks,
+		processors:        l.processors,
+		redactor:          l.redactor,
+		encoder:           l.encoder,
+		name:              l.name,
+		reportCaller:      l.reportCaller,
+		ctx:               l.ctx,
+		multiline:         l.multiline,
 	}
 }
 
@@ -658,10 +912,13 @@ func (l *Logger) shouldLog(level LogLevel) bool {
 	}
 
 	// Check context-based conditions
-	// Note: For now we use context.Background(), but this could be enhanced
-	// to support goroutine-local context or method-specific context
+	// against the context bound with ForContext, or context.Background()
+	ctx := l.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
 	for _, condition := range l.contextConditions {
-		if !condition(context.Background()) {
+		if !condition(ctx) {
 			return false
 		}
 	}
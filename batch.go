@@ -0,0 +1,134 @@
+package balogan
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"time"
+)
+
+// batcher collects items and flushes them in batches, either when BatchSize
+// items are pending or every FlushInterval. It is shared by the writers which
+// ship records to remote services.
+//
+// Errors of background flushes are passed to the error handler. Without an
+// error handler they are kept and returned by the next call to add, so they
+// are not lost silently.
+type batcher[T any] struct {
+	mu      sync.Mutex
+	items   []T
+	errs    []error
+	closed  bool
+	flushMu sync.Mutex
+
+	size         int
+	flushFunc    func([]T) error
+	errorHandler ErrorHandler
+
+	kick chan struct{}
+	stop chan struct{}
+	done chan struct{}
+}
+
+// newBatcher creates a batcher and starts its background flush loop.
+func newBatcher[T any](size int, interval time.Duration, errorHandler ErrorHandler, flush func([]T) error) *batcher[T] {
+	b := &batcher[T]{
+		size:         size,
+		flushFunc:    flush,
+		errorHandler: errorHandler,
+		kick:         make(chan struct{}, 1),
+		stop:         make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+	go b.loop(interval)
+	return b
+}
+
+// add queues an item. A full batch is flushed in the background.
+// It returns the errors of earlier background flushes when there is no error handler.
+func (b *batcher[T]) add(item T) error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return os.ErrClosed
+	}
+	b.items = append(b.items, item)
+	full := len(b.items) >= b.size
+	err := errors.Join(b.errs...)
+	b.errs = nil
+	b.mu.Unlock()
+
+	if full {
+		select {
+		case b.kick <- struct{}{}:
+		default:
+		}
+	}
+	return err
+}
+
+// flush sends all pending items and returns the error of the flush.
+func (b *batcher[T]) flush() error {
+	b.flushMu.Lock()
+	defer b.flushMu.Unlock()
+
+	var errs []error
+	for {
+		b.mu.Lock()
+		n := min(len(b.items), b.size)
+		batch := b.items[:n:n]
+		b.items = b.items[n:]
+		b.mu.Unlock()
+
+		if n == 0 {
+			return errors.Join(errs...)
+		}
+		if err := b.flushFunc(batch); err != nil {
+			errs = append(errs, err)
+		}
+	}
+}
+
+// close stops the background loop and flushes the remaining items.
+func (b *batcher[T]) close() error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return os.ErrClosed
+	}
+	b.closed = true
+	b.mu.Unlock()
+
+	close(b.stop)
+	<-b.done
+	return b.flush()
+}
+
+func (b *batcher[T]) loop(interval time.Duration) {
+	defer close(b.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.stop:
+			return
+		case <-ticker.C:
+		case <-b.kick:
+		}
+		if err := b.flush(); err != nil {
+			b.handleError(err)
+		}
+	}
+}
+
+func (b *batcher[T]) handleError(err error) {
+	if b.errorHandler != nil {
+		b.errorHandler.Handle(err)
+		return
+	}
+	b.mu.Lock()
+	b.errs = append(b.errs, err)
+	b.mu.Unlock()
+}
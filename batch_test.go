@@ -0,0 +1,92 @@
+package balogan
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBatcher_FlushesFullBatches(t *testing.T) {
+	var mu sync.Mutex
+	var batches [][]int
+	b := newBatcher(2, time.Hour, nil, func(items []int) error {
+		mu.Lock()
+		defer mu.Unlock()
+		batches = append(batches, items)
+		return nil
+	})
+
+	for i := range 5 {
+		if err := b.add(i); err != nil {
+			t.Fatalf("add failed: %v", err)
+		}
+	}
+	if err := b.close(); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+
+	total := 0
+	for _, batch := range batches {
+		if len(batch) > 2 {
+			t.Errorf("Batch exceeds size: %v", batch)
+		}
+		total += len(batch)
+	}
+	if total != 5 {
+		t.Errorf("Expected 5 items flushed, got %d", total)
+	}
+}
+
+func TestBatcher_Interval(t *testing.T) {
+	flushed := make(chan []string, 1)
+	b := newBatcher(100, 10*time.Millisecond, nil, func(items []string) error {
+		flushed <- items
+		return nil
+	})
+	defer b.close()
+
+	_ = b.add("tick")
+	select {
+	case items := <-flushed:
+		if len(items) != 1 || items[0] != "tick" {
+			t.Errorf("Unexpected batch %v", items)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected batch to be flushed on interval")
+	}
+}
+
+func TestBatcher_BackgroundErrorsReturnedByAdd(t *testing.T) {
+	flushErr := errors.New("flush failed")
+	b := newBatcher(1, time.Hour, nil, func([]int) error { return flushErr })
+	defer b.close()
+
+	_ = b.add(1)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if err := b.add(2); err != nil {
+			if !errors.Is(err, flushErr) {
+				t.Errorf("Expected flush error, got %v", err)
+			}
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("Expected background flush error to be returned by add")
+}
+
+func TestBatcher_Closed(t *testing.T) {
+	b := newBatcher(10, time.Hour, nil, func([]int) error { return nil })
+	if err := b.close(); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+	if err := b.add(1); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Expected os.ErrClosed, got %v", err)
+	}
+	if err := b.close(); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Expected os.ErrClosed on second close, got %v", err)
+	}
+}
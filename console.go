@@ -0,0 +1,91 @@
+package balogan
+
+import (
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultConsoleStderrLevel is the lowest level NewConsoleLogWriter sends to stderr.
+const DefaultConsoleStderrLevel = WarningLevel
+
+// ConsoleLogWriter writes log messages to stdout and stderr, split by level.
+// Messages at or above the stderr level go to stderr, all others to stdout.
+//
+// Unlike StdOutLogWriter, every message is written with a single write call,
+// terminated with a newline, so lines of concurrent writers do not interleave.
+// Writes are serialized, so the writer is safe for concurrent use.
+type ConsoleLogWriter struct {
+	mu          sync.Mutex
+	stdout      io.Writer
+	stderr      io.Writer
+	stderrLevel LogLevel
+}
+
+// NewConsoleLogWriter creates a new ConsoleLogWriter which sends WARNING and
+// above to os.Stderr and everything else to os.Stdout.
+//
+// Example:
+//
+//	logger := New(InfoLevel, NewConsoleLogWriter())
+//	logger.Info("to stdout")
+//	logger.Error("to stderr")
+func NewConsoleLogWriter() *ConsoleLogWriter {
+	return NewConsoleLogWriterTo(os.Stdout, os.Stderr, DefaultConsoleStderrLevel)
+}
+
+// NewConsoleLogWriterTo creates a new ConsoleLogWriter on top of arbitrary writers,
+// for example buffers which capture output in tests.
+//
+// Parameters:
+//
+//	stdout: The writer for messages below stderrLevel. os.Stdout is used when nil.
+//	stderr: The writer for messages at or above stderrLevel. os.Stderr is used when nil.
+//	stderrLevel: The lowest level sent to stderr.
+//
+// Example:
+//
+//	var stdout, stderr bytes.Buffer
+//	writer := NewConsoleLogWriterTo(&stdout, &stderr, ErrorLevel)
+func NewConsoleLogWriterTo(stdout, stderr io.Writer, stderrLevel LogLevel) *ConsoleLogWriter {
+	if stdout == nil {
+		stdout = os.Stdout
+	}
+	if stderr == nil {
+		stderr = os.Stderr
+	}
+	return &ConsoleLogWriter{stdout: stdout, stderr: stderr, stderrLevel: stderrLevel}
+}
+
+// Write writes the message to stdout, as it carries no level.
+func (w *ConsoleLogWriter) Write(bytes []byte) (int, error) {
+	record := &Record{Time: time.Now(), Level: InfoLevel, Message: string(bytes)}
+	if err := w.WriteRecord(record, bytes); err != nil {
+		return 0, err
+	}
+	return len(bytes), nil
+}
+
+// WriteRecord writes the line to stdout or stderr depending on the record level.
+func (w *ConsoleLogWriter) WriteRecord(record *Record, line []byte) error {
+	out := w.stdout
+	if record.Level >= w.stderrLevel {
+		out = w.stderr
+	}
+
+	if len(line) == 0 || line[len(line)-1] != '\n' {
+		line = append(line[:len(line):len(line)], '\n')
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	_, err := out.Write(line)
+	return err
+}
+
+// Close does nothing. The underlying writers, such as os.Stdout, are not closed.
+func (w *ConsoleLogWriter) Close() error {
+	return nil
+}
@@ -0,0 +1,86 @@
+package balogan
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestConsoleLogWriter_SplitsByLevel(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	writer := NewConsoleLogWriterTo(&stdout, &stderr, DefaultConsoleStderrLevel)
+	var _ RecordWriter = writer
+
+	logger := New(TraceLevel, writer)
+	logger.Debug("debug")
+	logger.Info("info")
+	logger.Warning("warning")
+	logger.Error("error")
+
+	if got := stdout.String(); got != "DEBUG debug\nINFO info\n" {
+		t.Errorf("stdout = %q, want messages below WARNING", got)
+	}
+	if got := stderr.String(); got != "WARNING warning\nERROR error\n" {
+		t.Errorf("stderr = %q, want WARNING and above", got)
+	}
+}
+
+func TestConsoleLogWriter_StderrLevel(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	logger := New(InfoLevel, NewConsoleLogWriterTo(&stdout, &stderr, ErrorLevel))
+	logger.Warning("warning")
+	logger.Error("error")
+
+	if got := stdout.String(); got != "WARNING warning\n" {
+		t.Errorf("stdout = %q, want WARNING below the threshold", got)
+	}
+	if got := stderr.String(); got != "ERROR error\n" {
+		t.Errorf("stderr = %q, want ERROR at the threshold", got)
+	}
+}
+
+// countingWriter counts write calls.
+type countingWriter struct {
+	bytes.Buffer
+	writes int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return w.Buffer.Write(p)
+}
+
+func TestConsoleLogWriter_SingleWrite(t *testing.T) {
+	out := &countingWriter{}
+	writer := NewConsoleLogWriterTo(out, out, DefaultConsoleStderrLevel)
+
+	if n, err := writer.Write([]byte("plain")); err != nil || n != 5 {
+		t.Fatalf("Write() = %d, %v", n, err)
+	}
+	_, _ = writer.Write([]byte("terminated\n"))
+
+	if out.writes != 2 {
+		t.Errorf("Expected one write call per message, got %d", out.writes)
+	}
+	if got := out.String(); got != "plain\nterminated\n" {
+		t.Errorf("Output = %q", got)
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, os.ErrClosed }
+
+func TestConsoleLogWriter_Errors(t *testing.T) {
+	writer := NewConsoleLogWriterTo(failingWriter{}, nil, DefaultConsoleStderrLevel)
+	if _, err := writer.Write([]byte("lost")); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Expected the write error, got %v", err)
+	}
+	if writer.stderr != os.Stderr {
+		t.Error("Expected os.Stderr for a nil writer")
+	}
+	if err := writer.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+}
@@ -17,3 +17,45 @@ func FromContext(ctx context.Context) (*Logger, bool) {
 	logger, ok := ctx.Value(contextKey).(*Logger)
 	return logger, ok
 }
+
+// ForContext returns a new Logger instance bound to ctx.
+// Context conditions are evaluated against ctx, and ctx is passed to
+// hooks and writers in Record.Context, for example to extract trace IDs.
+//
+// Parameters:
+//
+//	ctx: The context of the current request or operation.
+//
+// Example:
+//
+//	func handle(w http.ResponseWriter, r *http.Request) {
+//		logger.ForContext(r.Context()).Info("Handling request")
+//	}
+func (l *Logger) ForContext(ctx context.Context) *Logger {
+	return &Logger{
+		level:             l.level,
+		writers:           l.writers,
+		prefixes:          l.prefixes,
+		errorHandler:      l.errorHandler,
+		concurrency:       l.concurrency,
+		fields:            l.fields.Copy(),
+		fieldsFormatter:   l.fieldsFormatter,
+		conditions:        l.conditions,
+		levelConditions:   l.levelConditions,
+		contextConditions: l.contextConditions,
+		exitFunc:          l.exitFunc,
+		exitCode:          l.exitCode,
+		exitTimeout:       l.exitTimeout,
+		exitHandlers:      l.exitHandlers,
+		repanic:           l.repanic,
+		beforeHooks:       l.beforeHooks,
+		afterHooks:        l.afterHooks,
+		processors:        l.processors,
+		redactor:          l.redactor,
+		encoder:           l.encoder,
+		name:              l.name,
+		reportCaller:      l.reportCaller,
+		ctx:               ctx,
+		multiline:         l.multiline,
+	}
+}
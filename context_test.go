@@ -274,3 +274,30 @@ func TestContext_RealWorldUsage(t *testing.T) {
 		t.Errorf("Request processing failed: %v", err)
 	}
 }
+
+func TestLogger_ForContext(t *testing.T) {
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, "enabled")
+
+	mockWriter := &MockWriter{}
+	var seen context.Context
+	logger := New(InfoLevel, mockWriter).
+		WithContextCondition(func(ctx context.Context) bool { return ctx.Value(key{}) != nil }).
+		WithHook(BeforeWrite, NewHook(func(r *Record) error {
+			seen = r.Context
+			return nil
+		}))
+
+	logger.Info("without context")
+	if mockWriter.String() != "" {
+		t.Errorf("Context condition should reject the background context, got %q", mockWriter.String())
+	}
+
+	logger.ForContext(ctx).WithField("k", "v").Info("with context")
+	if mockWriter.String() == "" {
+		t.Error("Context condition should be evaluated against the bound context")
+	}
+	if seen != ctx {
+		t.Error("Record should carry the bound context")
+	}
+}
@@ -0,0 +1,210 @@
+package balogan
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ECSVersion is the Elastic Common Schema version reported in "ecs.version".
+const ECSVersion = "8.11.0"
+
+// DefaultECSFieldMapping maps common balogan field keys to ECS field names.
+// Keys which are neither mapped nor dotted are written to "labels".
+var DefaultECSFieldMapping = map[string]string{
+	"trace_id":       "trace.id",
+	"span_id":        "span.id",
+	"transaction_id": "transaction.id",
+	"user_id":        "user.id",
+	"request_id":     "http.request.id",
+	"host":           "host.name",
+	"hostname":       "host.name",
+	StackFieldKey:    "error.stack_trace",
+}
+
+// ECSFormatter formats fields as an ECS-shaped JSON object.
+// Fields are mapped with FieldMapping and DefaultECSFieldMapping,
+// dotted keys (such as "http.request.method") are kept as ECS field names,
+// errors stored under "error" or "err" become "error.message" and "error.type",
+// and all other fields are written to "labels" as strings.
+//
+// Use ECSEncoder to encode whole records, including timestamp, level and message.
+type ECSFormatter struct {
+	// FieldMapping maps balogan field keys to ECS field names.
+	// It takes precedence over DefaultECSFieldMapping.
+	FieldMapping map[string]string
+}
+
+func (f *ECSFormatter) Format(fields Fields) string {
+	if len(fields) == 0 {
+		return ""
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	obj := jsonObjectWriter{buf: &buf}
+	writeECSFields(&obj, &buf, fields, f.FieldMapping, nil)
+	buf.WriteByte('}')
+	return buf.String()
+}
+
+// ECSEncoder encodes whole records as Elastic Common Schema JSON documents.
+// Fields are mapped the same way as by ECSFormatter.
+//
+// Example output:
+//
+//	{"@timestamp":"2024-12-13T15:30:45.123Z","log.level":"info","message":"Order created",
+//	 "ecs.version":"8.11.0","service.name":"shop","trace.id":"abc","labels":{"order":"42"}}
+type ECSEncoder struct {
+	// ServiceName is written as "service.name" when set.
+	ServiceName string
+	// ServiceVersion is written as "service.version" when set.
+	ServiceVersion string
+	// ServiceEnvironment is written as "service.environment" when set.
+	ServiceEnvironment string
+
+	// FieldMapping maps balogan field keys to ECS field names.
+	// It takes precedence over DefaultECSFieldMapping.
+	FieldMapping map[string]string
+}
+
+func (e *ECSEncoder) Encode(record *Record) string {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+
+	obj := jsonObjectWriter{buf: &buf}
+	obj.field("@timestamp", record.Time.UTC().Format("2006-01-02T15:04:05.000Z07:00"))
+	obj.field("log.level", strings.ToLower(record.Level.String()))
+	obj.field("message", record.Message)
+	obj.field("ecs.version", ECSVersion)
+
+	reserved := map[string]bool{
+		"@timestamp":  true,
+		"log.level":   true,
+		"message":     true,
+		"ecs.version": true,
+	}
+	optional := []struct {
+		key   string
+		value string
+	}{
+		{"service.name", e.ServiceName},
+		{"service.version", e.ServiceVersion},
+		{"service.environment", e.ServiceEnvironment},
+		{"log.logger", record.Logger},
+	}
+	for _, o := range optional {
+		if o.value != "" {
+			obj.field(o.key, o.value)
+			reserved[o.key] = true
+		}
+	}
+
+	if record.Caller != "" {
+		file, line, _ := strings.Cut(record.Caller, ":")
+		obj.field("log.origin.file.name", file)
+		reserved["log.origin.file.name"] = true
+		if n, err := strconv.Atoi(line); err == nil {
+			obj.field("log.origin.file.line", n)
+			reserved["log.origin.file.line"] = true
+		}
+	}
+
+	writeECSFields(&obj, &buf, record.Fields, e.FieldMapping, reserved)
+
+	buf.WriteByte('}')
+	return buf.String()
+}
+
+// WithECS returns a new Logger instance which encodes records as
+// Elastic Common Schema JSON with the given service name.
+// This is a convenient shortcut for WithEncoder(&ECSEncoder{ServiceName: serviceName}).
+//
+// Example:
+//
+//	logger.WithECS("shop").WithField("trace_id", "abc").Info("Order created")
+//	// Output: {"@timestamp":"...","log.level":"info","message":"Order created","ecs.version":"8.11.0","service.name":"shop","trace.id":"abc"}
+func (l *Logger) WithECS(serviceName string) *Logger {
+	return l.WithEncoder(&ECSEncoder{ServiceName: serviceName})
+}
+
+// writeECSFields maps fields to ECS names and writes them as members of obj.
+// Mapped fields are written first sorted by name, followed by the "labels" object.
+// Fields mapped onto a reserved name are written to labels instead.
+func writeECSFields(obj *jsonObjectWriter, buf *bytes.Buffer, fields Fields, mapping map[string]string, reserved map[string]bool) {
+	mapped := make(map[string]interface{})
+	labels := make(map[string]string)
+
+	for _, k := range sortedKeys(fields) {
+		v := fields[k]
+
+		if k == "error" || k == "err" {
+			if err, ok := v.(error); ok {
+				mapped["error.message"] = err.Error()
+				mapped["error.type"] = fmt.Sprintf("%T", err)
+				continue
+			}
+			if s, ok := v.(string); ok {
+				mapped["error.message"] = s
+				continue
+			}
+		}
+
+		name, ok := mapping[k]
+		if !ok {
+			name, ok = DefaultECSFieldMapping[k]
+		}
+		if !ok && strings.Contains(k, ".") {
+			name, ok = k, true
+		}
+
+		if !ok || reserved[name] {
+			labels[ecsLabelKey(k)] = logfmtString(v)
+			continue
+		}
+		if err, isErr := v.(error); isErr {
+			v = err.Error()
+		}
+		mapped[name] = v
+	}
+
+	names := make([]string, 0, len(mapped))
+	for name := range mapped {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		obj.field(name, mapped[name])
+	}
+
+	if len(labels) == 0 {
+		return
+	}
+
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	obj.key("labels")
+	buf.WriteByte('{')
+	nested := jsonObjectWriter{buf: buf}
+	for _, k := range keys {
+		nested.field(k, labels[k])
+	}
+	buf.WriteByte('}')
+}
+
+// ecsLabelKey replaces characters not allowed in ECS label keys
+// (dots, spaces and '*') with underscores.
+func ecsLabelKey(key string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '.' || r == ' ' || r == '*' || r == '"' || r == '\\' {
+			return '_'
+		}
+		return r
+	}, key)
+}
@@ -0,0 +1,113 @@
+package balogan
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestECSEncoder_Encode(t *testing.T) {
+	record := &Record{
+		Time:    time.Date(2024, 12, 13, 15, 30, 45, 123000000, time.UTC),
+		Level:   ErrorLevel,
+		Message: "Order failed",
+		Fields: Fields{
+			"trace_id":            "abc123",
+			"order":               42,
+			"http.request.method": "POST",
+			"error":               errors.New("card declined"),
+			"customer":            "vip",
+		},
+		Logger: "shop.orders",
+		Caller: "orders/create.go:57",
+	}
+
+	encoder := &ECSEncoder{ServiceName: "shop", ServiceVersion: "1.2.3"}
+	got := encoder.Encode(record)
+
+	expected := `{"@timestamp":"2024-12-13T15:30:45.123Z","log.level":"error","message":"Order failed","ecs.version":"` + ECSVersion + `",` +
+		`"service.name":"shop","service.version":"1.2.3","log.logger":"shop.orders",` +
+		`"log.origin.file.name":"orders/create.go","log.origin.file.line":57,` +
+		`"error.message":"card declined","error.type":"*errors.errorString","http.request.method":"POST","trace.id":"abc123",` +
+		`"labels":{"customer":"vip","order":"42"}}`
+	if got != expected {
+		t.Errorf("Encode() =\n%s\nwant\n%s", got, expected)
+	}
+	if !json.Valid([]byte(got)) {
+		t.Error("Encoded record is not valid JSON")
+	}
+}
+
+func TestECSEncoder_FieldMapping(t *testing.T) {
+	record := &Record{
+		Time:    time.Now(),
+		Level:   InfoLevel,
+		Message: "mapped",
+		Fields: Fields{
+			"uid":     7,
+			"message": "clashes with message",
+			"user_id": 9,
+		},
+	}
+
+	encoder := &ECSEncoder{FieldMapping: map[string]string{"uid": "user.name", "user_id": "user.email"}}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal([]byte(encoder.Encode(record)), &decoded); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+
+	if decoded["user.name"] != float64(7) {
+		t.Errorf("Custom mapping should be applied, got %v", decoded)
+	}
+	if decoded["user.email"] != float64(9) {
+		t.Errorf("Custom mapping should override default mapping, got %v", decoded)
+	}
+	if decoded["message"] != "mapped" {
+		t.Errorf("Message should not be overwritten, got %v", decoded["message"])
+	}
+	labels, _ := decoded["labels"].(map[string]interface{})
+	if labels["message"] != "clashes with message" {
+		t.Errorf("Clashing field should go to labels, got %v", labels)
+	}
+}
+
+func TestECSFormatter_Format(t *testing.T) {
+	formatter := &ECSFormatter{}
+
+	if got := formatter.Format(Fields{}); got != "" {
+		t.Errorf("Empty fields should format to empty string, got %q", got)
+	}
+
+	got := formatter.Format(Fields{"trace_id": "abc", "team": "payments", "a.b": true})
+	expected := `{"a.b":true,"trace.id":"abc","labels":{"team":"payments"}}`
+	if got != expected {
+		t.Errorf("Format() = %s, want %s", got, expected)
+	}
+}
+
+func TestECSLabelKey(t *testing.T) {
+	if got := ecsLabelKey("a.b c*d"); got != "a_b_c_d" {
+		t.Errorf("ecsLabelKey() = %q, want %q", got, "a_b_c_d")
+	}
+}
+
+func TestLogger_WithECS(t *testing.T) {
+	mockWriter := &MockWriter{}
+	logger := New(InfoLevel, mockWriter).WithECS("shop").WithField("order_id", "A-1")
+
+	logger.Info("Order created")
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(mockWriter.Bytes(), &decoded); err != nil {
+		t.Fatalf("Output is not valid JSON: %v\n%s", err, mockWriter.String())
+	}
+	if decoded["service.name"] != "shop" || decoded["log.level"] != "info" || decoded["message"] != "Order created" {
+		t.Errorf("Unexpected ECS output: %v", decoded)
+	}
+	if !strings.Contains(mockWriter.String(), `"labels":{"order_id":"A-1"}`) {
+		t.Errorf("Expected fields in labels, got %s", mockWriter.String())
+	}
+}
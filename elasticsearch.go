@@ -0,0 +1,328 @@
+package balogan
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Default Elasticsearch writer settings.
+const (
+	// DefaultElasticsearchURL is the address of a local Elasticsearch or OpenSearch node.
+	DefaultElasticsearchURL = "http://localhost:9200"
+	// DefaultElasticsearchIndex is the index pattern, expanded with the record's UTC time.
+	DefaultElasticsearchIndex = "logs-%Y.%m.%d"
+	// DefaultElasticsearchBatchSize is the maximum number of documents per bulk request.
+	DefaultElasticsearchBatchSize = 500
+	// DefaultElasticsearchFlushInterval is how often pending documents are sent.
+	DefaultElasticsearchFlushInterval = time.Second
+	// DefaultElasticsearchTimeout limits every bulk request.
+	DefaultElasticsearchTimeout = 30 * time.Second
+	// DefaultElasticsearchMaxRetries is the number of retries of failed requests and rejected documents.
+	DefaultElasticsearchMaxRetries = 3
+	// DefaultElasticsearchRetryBackoff is the delay before the first retry.
+	DefaultElasticsearchRetryBackoff = 500 * time.Millisecond
+	// DefaultElasticsearchMaxBackoff caps the delay between retries.
+	DefaultElasticsearchMaxBackoff = 30 * time.Second
+)
+
+// ElasticsearchConfig configures an ElasticsearchWriter.
+type ElasticsearchConfig struct {
+	// URL is the base URL of the cluster. DefaultElasticsearchURL is used when empty.
+	URL string
+	// Index is the target index. It may contain strftime directives such as
+	// %Y, %m and %d, which are expanded with the record's time in UTC.
+	// DefaultElasticsearchIndex is used when empty.
+	Index string
+	// Action is the bulk action, "create" or "index". "create" is used when empty,
+	// which also works with data streams.
+	Action string
+
+	// Username and Password enable basic authentication.
+	Username string
+	Password string
+	// APIKey is sent as "Authorization: ApiKey <APIKey>".
+	APIKey string
+	// Headers are added to every request.
+	Headers map[string]string
+	// Compress gzips request bodies.
+	Compress bool
+
+	// Encoder encodes the document. A JSONEncoder with "@timestamp" as time key is used when nil.
+	// The encoder must produce a single line JSON object.
+	Encoder Encoder
+
+	// BatchSize is the maximum number of documents per request.
+	// DefaultElasticsearchBatchSize is used when zero.
+	BatchSize int
+	// FlushInterval is how often pending documents are sent.
+	// DefaultElasticsearchFlushInterval is used when zero.
+	FlushInterval time.Duration
+	// Timeout limits every request. DefaultElasticsearchTimeout is used when zero.
+	Timeout time.Duration
+	// MaxRetries is the number of retries of failed requests and of documents rejected
+	// with 429 or 5xx. DefaultElasticsearchMaxRetries is used when zero,
+	// a negative value disables retries.
+	MaxRetries int
+	// RetryBackoff is the delay before the first retry, doubled for every further retry
+	// up to MaxBackoff. DefaultElasticsearchRetryBackoff and DefaultElasticsearchMaxBackoff
+	// are used when zero.
+	RetryBackoff time.Duration
+	MaxBackoff   time.Duration
+
+	// Client sends the requests. http.DefaultClient is used when nil.
+	Client *http.Client
+	// ErrorHandler receives failed requests and documents which could not be indexed.
+	// When nil, these errors are returned by the next WriteRecord call.
+	ErrorHandler ErrorHandler
+}
+
+// ElasticsearchItemError describes a document rejected by the bulk API.
+type ElasticsearchItemError struct {
+	// Index is the index the document was sent to.
+	Index string
+	// Status is the HTTP status of the item.
+	Status int
+	// Type and Reason are taken from the item's error.
+	Type   string
+	Reason string
+}
+
+func (e *ElasticsearchItemError) Error() string {
+	return fmt.Sprintf("elasticsearch: index %q: status %d: %s: %s", e.Index, e.Status, e.Type, e.Reason)
+}
+
+// ElasticsearchWriter ships records to Elasticsearch or OpenSearch through the _bulk API.
+//
+// Documents are buffered and sent when BatchSize documents are pending, every
+// FlushInterval, on Flush and on Close. Failed requests are retried on network
+// errors, 429 and 5xx responses. The response is checked for per-item errors:
+// documents rejected with 429 or 5xx (for example a full write queue) are retried,
+// other rejections (for example mapping conflicts) are reported as
+// *ElasticsearchItemError to the ErrorHandler.
+type ElasticsearchWriter struct {
+	cfg     ElasticsearchConfig
+	bulkURL string
+	headers map[string]string
+	retry   retryPolicy
+	batcher *batcher[elasticsearchDocument]
+}
+
+type elasticsearchDocument struct {
+	index string
+	body  string
+}
+
+// NewElasticsearchWriter creates a new ElasticsearchWriter.
+func NewElasticsearchWriter(cfg *ElasticsearchConfig) (*ElasticsearchWriter, error) {
+	c := ElasticsearchConfig{}
+	if cfg != nil {
+		c = *cfg
+	}
+
+	if c.URL == "" {
+		c.URL = DefaultElasticsearchURL
+	}
+	if u, err := url.Parse(c.URL); err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("elasticsearch: invalid url %q", c.URL)
+	}
+	if c.Index == "" {
+		c.Index = DefaultElasticsearchIndex
+	}
+	if c.Action == "" {
+		c.Action = "create"
+	}
+	if c.Action != "create" && c.Action != "index" {
+		return nil, fmt.Errorf("elasticsearch: unsupported action %q", c.Action)
+	}
+	if c.Encoder == nil {
+		c.Encoder = &JSONEncoder{TimeKey: "@timestamp"}
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = DefaultElasticsearchBatchSize
+	}
+	if c.FlushInterval <= 0 {
+		c.FlushInterval = DefaultElasticsearchFlushInterval
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = DefaultElasticsearchTimeout
+	}
+	if c.MaxRetries == 0 {
+		c.MaxRetries = DefaultElasticsearchMaxRetries
+	}
+	if c.RetryBackoff <= 0 {
+		c.RetryBackoff = DefaultElasticsearchRetryBackoff
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = DefaultElasticsearchMaxBackoff
+	}
+	if c.Client == nil {
+		c.Client = http.DefaultClient
+	}
+
+	headers := map[string]string{"Content-Type": "application/x-ndjson"}
+	switch {
+	case c.APIKey != "":
+		headers["Authorization"] = "ApiKey " + c.APIKey
+	case c.Username != "":
+		headers["Authorization"] = "Basic " + base64.StdEncoding.EncodeToString([]byte(c.Username+":"+c.Password))
+	}
+	for k, v := range c.Headers {
+		headers[k] = v
+	}
+
+	w := &ElasticsearchWriter{
+		cfg:     c,
+		bulkURL: strings.TrimRight(c.URL, "/") + "/_bulk",
+		headers: headers,
+		retry:   retryPolicy{maxRetries: c.MaxRetries, backoff: c.RetryBackoff, maxBackoff: c.MaxBackoff, jitter: true},
+	}
+	w.batcher = newBatcher(c.BatchSize, c.FlushInterval, c.ErrorHandler, w.ship)
+	return w, nil
+}
+
+// Write queues bytes as the message of an INFO level document.
+func (w *ElasticsearchWriter) Write(bytes []byte) (int, error) {
+	record := &Record{Time: time.Now(), Level: InfoLevel, Message: string(bytes)}
+	if err := w.WriteRecord(record, bytes); err != nil {
+		return 0, err
+	}
+	return len(bytes), nil
+}
+
+// WriteRecord encodes the record and queues it for the bulk API.
+func (w *ElasticsearchWriter) WriteRecord(record *Record, line []byte) error {
+	return w.batcher.add(elasticsearchDocument{
+		index: strftime(w.cfg.Index, record.Time.UTC()),
+		body:  w.cfg.Encoder.Encode(record),
+	})
+}
+
+// Flush sends all pending documents.
+func (w *ElasticsearchWriter) Flush() error {
+	return w.batcher.flush()
+}
+
+// Close sends all pending documents and stops the writer.
+func (w *ElasticsearchWriter) Close() error {
+	return w.batcher.close()
+}
+
+type elasticsearchBulkResponse struct {
+	Errors bool                                     `json:"errors"`
+	Items  []map[string]elasticsearchBulkItemResult `json:"items"`
+}
+
+type elasticsearchBulkItemResult struct {
+	Index  string `json:"_index"`
+	Status int    `json:"status"`
+	Error  *struct {
+		Type   string `json:"type"`
+		Reason string `json:"reason"`
+	} `json:"error"`
+}
+
+// ship sends documents, retrying failed requests and rejected documents,
+// and returns the errors of documents which could not be indexed.
+func (w *ElasticsearchWriter) ship(docs []elasticsearchDocument) error {
+	ctx := context.Background()
+
+	var failed []error
+	pending := docs
+	for attempt := 0; ; attempt++ {
+		retry, rejected, err := w.bulk(ctx, pending)
+		if err != nil {
+			if attempt >= w.retry.maxRetries || !retryable(err) {
+				return fmt.Errorf("elasticsearch: sending %d documents: %w", len(pending), errors.Join(append(failed, err)...))
+			}
+			if waitErr := w.retry.wait(ctx, attempt, err); waitErr != nil {
+				return errors.Join(append(failed, err, waitErr)...)
+			}
+			continue
+		}
+
+		failed = append(failed, rejected...)
+		if len(retry) == 0 {
+			return errors.Join(failed...)
+		}
+		if attempt >= w.retry.maxRetries {
+			for _, item := range retry {
+				failed = append(failed, item.err)
+			}
+			return errors.Join(failed...)
+		}
+
+		pending = make([]elasticsearchDocument, len(retry))
+		for i, item := range retry {
+			pending[i] = item.doc
+		}
+		if waitErr := w.retry.wait(ctx, attempt, retry[0].err); waitErr != nil {
+			return errors.Join(append(failed, waitErr)...)
+		}
+	}
+}
+
+type elasticsearchRetry struct {
+	doc elasticsearchDocument
+	err *ElasticsearchItemError
+}
+
+// bulk sends one bulk request. It returns the documents which should be
+// retried, the errors of permanently rejected documents, and the error
+// of the request itself.
+func (w *ElasticsearchWriter) bulk(ctx context.Context, docs []elasticsearchDocument) ([]elasticsearchRetry, []error, error) {
+	var body bytes.Buffer
+	for _, doc := range docs {
+		body.WriteString(`{"` + w.cfg.Action + `":{"_index":`)
+		writeJSONValue(&body, doc.index)
+		body.WriteString("}}\n")
+		body.WriteString(doc.body)
+		body.WriteByte('\n')
+	}
+
+	req := httpRequest{method: http.MethodPost, url: w.bulkURL, headers: w.headers, body: body.Bytes(), gzip: w.cfg.Compress}
+	respBody, err := req.send(ctx, w.cfg.Client, w.cfg.Timeout)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var resp elasticsearchBulkResponse
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return nil, nil, &permanentError{fmt.Errorf("invalid bulk response: %w", err)}
+	}
+	if !resp.Errors {
+		return nil, nil, nil
+	}
+	if len(resp.Items) != len(docs) {
+		return nil, nil, &permanentError{fmt.Errorf("bulk response has %d items for %d documents", len(resp.Items), len(docs))}
+	}
+
+	var retry []elasticsearchRetry
+	var rejected []error
+	for i, item := range resp.Items {
+		for _, result := range item {
+			if result.Error == nil && result.Status < 300 {
+				continue
+			}
+
+			itemErr := &ElasticsearchItemError{Index: docs[i].index, Status: result.Status}
+			if result.Error != nil {
+				itemErr.Type = result.Error.Type
+				itemErr.Reason = result.Error.Reason
+			}
+			if result.Status == http.StatusTooManyRequests || result.Status >= 500 {
+				retry = append(retry, elasticsearchRetry{doc: docs[i], err: itemErr})
+			} else {
+				rejected = append(rejected, itemErr)
+			}
+		}
+	}
+	return retry, rejected, nil
+}
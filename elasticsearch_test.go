@@ -0,0 +1,228 @@
+package balogan
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+type bulkAction struct {
+	action string
+	index  string
+	doc    map[string]interface{}
+}
+
+func decodeBulk(body []byte) ([]bulkAction, error) {
+	var actions []bulkAction
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		var meta map[string]map[string]string
+		if err := json.Unmarshal(scanner.Bytes(), &meta); err != nil {
+			return nil, err
+		}
+		var action bulkAction
+		for name, params := range meta {
+			action.action = name
+			action.index = params["_index"]
+		}
+		if !scanner.Scan() {
+			return nil, fmt.Errorf("missing document after %q", action.action)
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &action.doc); err != nil {
+			return nil, err
+		}
+		actions = append(actions, action)
+	}
+	return actions, scanner.Err()
+}
+
+// respondBulk answers a _bulk request with the status itemStatus decides for every item.
+func respondBulk(itemStatus func(request int, action bulkAction) (int, string)) func(http.ResponseWriter, int, []bulkAction) {
+	return func(w http.ResponseWriter, request int, actions []bulkAction) {
+		resp := map[string]interface{}{"errors": false}
+		var items []interface{}
+		for _, action := range actions {
+			status, errType := itemStatus(request, action)
+			result := map[string]interface{}{"_index": action.index, "status": status}
+			if errType != "" {
+				result["error"] = map[string]string{"type": errType, "reason": "rejected by test"}
+				resp["errors"] = true
+			}
+			items = append(items, map[string]interface{}{action.action: result})
+		}
+		resp["items"] = items
+		_ = json.NewEncoder(w).Encode(resp)
+	}
+}
+
+func TestElasticsearchWriter_Bulk(t *testing.T) {
+	server := newRecordingServer(t, decodeBulk)
+	server.respond = respondBulk(func(int, bulkAction) (int, string) { return http.StatusCreated, "" })
+	writer, err := NewElasticsearchWriter(&ElasticsearchConfig{
+		URL:           server.URL,
+		Index:         "app-%Y.%m.%d",
+		APIKey:        "key",
+		FlushInterval: time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("NewElasticsearchWriter failed: %v", err)
+	}
+
+	logger := New(InfoLevel, writer)
+	logger.WithField("user", "john").Info("logged in")
+	logger.Error("failed")
+
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	requests := server.captured()
+	if len(requests) != 1 || len(requests[0].body) != 2 {
+		t.Fatalf("Expected one bulk request with 2 documents, got %v", requests)
+	}
+	if requests[0].path != "/_bulk" {
+		t.Errorf("Expected a request to /_bulk, got %q", requests[0].path)
+	}
+	if got := requests[0].header.Get("Authorization"); got != "ApiKey key" {
+		t.Errorf("Expected API key authorization, got %q", got)
+	}
+
+	action := requests[0].body[0]
+	expectedIndex := "app-" + time.Now().UTC().Format("2006.01.02")
+	if action.action != "create" || action.index != expectedIndex {
+		t.Errorf("Expected create into %q, got %s into %q", expectedIndex, action.action, action.index)
+	}
+	if action.doc["msg"] != "logged in" || action.doc["user"] != "john" || action.doc["@timestamp"] == nil {
+		t.Errorf("Unexpected document %v", action.doc)
+	}
+}
+
+func TestElasticsearchWriter_RetriesRejectedItems(t *testing.T) {
+	server := newRecordingServer(t, decodeBulk)
+	server.respond = respondBulk(func(request int, action bulkAction) (int, string) {
+		if request == 0 && action.doc["msg"] == "second" {
+			return http.StatusTooManyRequests, "es_rejected_execution_exception"
+		}
+		return http.StatusCreated, ""
+	})
+	writer, err := NewElasticsearchWriter(&ElasticsearchConfig{URL: server.URL, FlushInterval: time.Hour, RetryBackoff: time.Millisecond})
+	if err != nil {
+		t.Fatalf("NewElasticsearchWriter failed: %v", err)
+	}
+	defer writer.Close()
+
+	logger := New(InfoLevel, writer)
+	logger.Info("first")
+	logger.Info("second")
+
+	if err := writer.Flush(); err != nil {
+		t.Fatalf("Flush should succeed after retrying the rejected item, got %v", err)
+	}
+	requests := server.accepted()
+	if len(requests) != 2 || len(requests[1]) != 1 || requests[1][0].doc["msg"] != "second" {
+		t.Errorf("Expected only the rejected document to be retried, got %v", requests)
+	}
+}
+
+func TestElasticsearchWriter_PermanentItemErrors(t *testing.T) {
+	server := newRecordingServer(t, decodeBulk)
+	server.respond = respondBulk(func(request int, action bulkAction) (int, string) {
+		if action.doc["msg"] == "bad" {
+			return http.StatusBadRequest, "mapper_parsing_exception"
+		}
+		return http.StatusCreated, ""
+	})
+
+	handled := make(chan error, 1)
+	writer, err := NewElasticsearchWriter(&ElasticsearchConfig{
+		URL:           server.URL,
+		FlushInterval: 10 * time.Millisecond,
+		ErrorHandler:  ErrorHandlerFunc(func(err error) { handled <- err }),
+	})
+	if err != nil {
+		t.Fatalf("NewElasticsearchWriter failed: %v", err)
+	}
+	defer writer.Close()
+
+	logger := New(InfoLevel, writer)
+	logger.Info("good")
+	logger.Info("bad")
+
+	select {
+	case err := <-handled:
+		var itemErr *ElasticsearchItemError
+		if !errors.As(err, &itemErr) {
+			t.Fatalf("Expected ElasticsearchItemError, got %v", err)
+		}
+		if itemErr.Status != http.StatusBadRequest || itemErr.Type != "mapper_parsing_exception" {
+			t.Errorf("Unexpected item error %+v", itemErr)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected permanent item error to be handled")
+	}
+
+	if got := len(server.captured()); got != 1 {
+		t.Errorf("Permanent item errors should not be retried, got %d requests", got)
+	}
+}
+
+func TestElasticsearchWriter_RetriesExhausted(t *testing.T) {
+	server := newRecordingServer(t, decodeBulk)
+	server.respond = respondBulk(func(int, bulkAction) (int, string) {
+		return http.StatusServiceUnavailable, "unavailable_shards_exception"
+	})
+	writer, err := NewElasticsearchWriter(&ElasticsearchConfig{URL: server.URL, MaxRetries: 2, FlushInterval: time.Hour, RetryBackoff: time.Millisecond})
+	if err != nil {
+		t.Fatalf("NewElasticsearchWriter failed: %v", err)
+	}
+	defer writer.Close()
+
+	New(InfoLevel, writer).Info("never indexed")
+	err = writer.Flush()
+
+	var itemErr *ElasticsearchItemError
+	if !errors.As(err, &itemErr) || itemErr.Status != http.StatusServiceUnavailable {
+		t.Fatalf("Expected item error after retries, got %v", err)
+	}
+	if got := len(server.captured()); got != 3 {
+		t.Errorf("Expected 1 attempt and 2 retries, got %d requests", got)
+	}
+}
+
+func TestElasticsearchWriter_RequestError(t *testing.T) {
+	server := newRecordingServer(t, decodeBulk)
+	server.failures = 1
+	server.status = http.StatusUnauthorized
+
+	writer, err := NewElasticsearchWriter(&ElasticsearchConfig{URL: server.URL, Username: "elastic", Password: "x", FlushInterval: time.Hour})
+	if err != nil {
+		t.Fatalf("NewElasticsearchWriter failed: %v", err)
+	}
+	defer writer.Close()
+
+	New(InfoLevel, writer).Info("denied")
+	err = writer.Flush()
+
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("Expected HTTP 401 error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "elasticsearch") {
+		t.Errorf("Expected error to name the writer, got %v", err)
+	}
+}
+
+func TestNewElasticsearchWriter_InvalidConfig(t *testing.T) {
+	if _, err := NewElasticsearchWriter(&ElasticsearchConfig{URL: "localhost:9200"}); err == nil {
+		t.Error("Expected error for url without scheme")
+	}
+	if _, err := NewElasticsearchWriter(&ElasticsearchConfig{Action: "delete"}); err == nil {
+		t.Error("Expected error for unsupported action")
+	}
+}
@@ -0,0 +1,303 @@
+package balogan
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Encoder renders a whole record into a single log line.
+// When a logger has an Encoder, it replaces the default
+// "LEVEL prefixes fields message" text line.
+//
+// Prefix builders are not part of the record encoding,
+// their rendered output is available in Record.Prefixes.
+type Encoder interface {
+	Encode(record *Record) string
+}
+
+// TextEncoder encodes the record as the default "LEVEL prefixes fields message"
+// text line. It is useful to give a single writer a human-readable format
+// while the logger uses another encoder, see WithWriter.
+//
+// Example output:
+//
+//	INFO user=john User logged in
+type TextEncoder struct {
+	// FieldsFormatter formats the fields. DefaultFieldsFormatter is used when nil.
+	FieldsFormatter FieldsFormatter
+}
+
+func (e *TextEncoder) Encode(record *Record) string {
+	formatter := e.FieldsFormatter
+	if formatter == nil {
+		formatter = DefaultFieldsFormatter
+	}
+	return textLine(record, formatter)
+}
+
+// Default key names used by JSONEncoder.
+const (
+	DefaultTimeKey    = "time"
+	DefaultLevelKey   = "level"
+	DefaultMessageKey = "msg"
+	DefaultLoggerKey  = "logger"
+	DefaultCallerKey  = "caller"
+)
+
+// JSONEncoder encodes the whole record as one JSON object without line breaks.
+//
+// Keys are written in a stable order: time, level, message, logger, caller,
+// followed by the fields sorted by key. Logger and caller are omitted when empty.
+// Fields whose keys clash with one of the record keys are written as "fields.<key>".
+//
+// Example output:
+//
+//	{"time":"2024-12-13T15:30:45.123Z","level":"INFO","msg":"User logged in","user":"john"}
+type JSONEncoder struct {
+	// Key names. The Default*Key constants are used for empty values.
+	TimeKey    string
+	LevelKey   string
+	MessageKey string
+	LoggerKey  string
+	CallerKey  string
+
+	// FieldsKey nests all fields under the given key.
+	// Fields are written at the top level when empty.
+	FieldsKey string
+
+	// TimeFormat is the time.Format layout of the time value.
+	// time.RFC3339Nano is used when empty.
+	TimeFormat string
+
+	// LowercaseLevel writes levels as "info" instead of "INFO".
+	LowercaseLevel bool
+}
+
+func (e *JSONEncoder) Encode(record *Record) string {
+	timeKey := keyOrDefault(e.TimeKey, DefaultTimeKey)
+	levelKey := keyOrDefault(e.LevelKey, DefaultLevelKey)
+	messageKey := keyOrDefault(e.MessageKey, DefaultMessageKey)
+	loggerKey := keyOrDefault(e.LoggerKey, DefaultLoggerKey)
+	callerKey := keyOrDefault(e.CallerKey, DefaultCallerKey)
+
+	timeFormat := e.TimeFormat
+	if timeFormat == "" {
+		timeFormat = time.RFC3339Nano
+	}
+
+	level := record.Level.String()
+	if e.LowercaseLevel {
+		level = strings.ToLower(level)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+
+	obj := jsonObjectWriter{buf: &buf}
+	obj.field(timeKey, record.Time.Format(timeFormat))
+	obj.field(levelKey, level)
+	obj.field(messageKey, record.Message)
+	if record.Logger != "" {
+		obj.field(loggerKey, record.Logger)
+	}
+	if record.Caller != "" {
+		obj.field(callerKey, record.Caller)
+	}
+
+	if len(record.Fields) > 0 {
+		if e.FieldsKey != "" {
+			obj.key(e.FieldsKey)
+			buf.WriteByte('{')
+			nested := jsonObjectWriter{buf: &buf}
+			for _, k := range sortedKeys(record.Fields) {
+				nested.field(k, record.Fields[k])
+			}
+			buf.WriteByte('}')
+		} else {
+			reserved := map[string]bool{
+				timeKey: true, levelKey: true, messageKey: true, loggerKey: true, callerKey: true,
+			}
+			for _, k := range sortedKeys(record.Fields) {
+				key := k
+				if reserved[key] {
+					key = "fields." + key
+				}
+				obj.field(key, record.Fields[k])
+			}
+		}
+	}
+
+	buf.WriteByte('}')
+	return buf.String()
+}
+
+// jsonObjectWriter writes comma separated members of a JSON object.
+type jsonObjectWriter struct {
+	buf     *bytes.Buffer
+	written bool
+}
+
+func (w *jsonObjectWriter) key(key string) {
+	if w.written {
+		w.buf.WriteByte(',')
+	}
+	w.written = true
+
+	writeJSONValue(w.buf, key)
+	w.buf.WriteByte(':')
+}
+
+func (w *jsonObjectWriter) field(key string, value interface{}) {
+	w.key(key)
+	writeJSONValue(w.buf, value)
+}
+
+// writeJSONValue writes value as JSON. Errors are written as their message
+// and values which cannot be marshalled fall back to their fmt representation.
+func writeJSONValue(buf *bytes.Buffer, value interface{}) {
+	if err, ok := value.(error); ok {
+		value = err.Error()
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		data, _ = json.Marshal(fmt.Sprintf("%v", value))
+	}
+	buf.Write(data)
+}
+
+func keyOrDefault(key, defaultKey string) string {
+	if key == "" {
+		return defaultKey
+	}
+	return key
+}
+
+func sortedKeys(fields Fields) []string {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// WithEncoder returns a new Logger instance which renders whole records with the given encoder
+// instead of the default text line. Passing nil restores the default text line.
+//
+// Parameters:
+//
+//	encoder: The Encoder to use for log lines.
+//
+// Example:
+//
+//	logger.WithEncoder(&JSONEncoder{TimeKey: "@timestamp", LevelKey: "severity"}).
+//		WithField("user", "john").Info("User logged in")
+//	// Output: {"@timestamp":"...","severity":"INFO","msg":"User logged in","user":"john"}
+func (l *Logger) WithEncoder(encoder Encoder) *Logger {
+	return &Logger{
+		level:             l.level,
+		writers:           l.writers,
+		prefixes:          l.prefixes,
+		errorHandler:      l.errorHandler,
+		concurrency:       l.concurrency,
+		fields:            l.fields.Copy(),
+		fieldsFormatter:   l.fieldsFormatter,
+		conditions:        l.conditions,
+		levelConditions:   l.levelConditions,
+		contextConditions: l.contextConditions,
+		exitFunc:          l.exitFunc,
+		exitCode:          l.exitCode,
+		exitTimeout:       l.exitTimeout,
+		exitHandlers:      l.exitHandlers,
+		repanic:           l.repanic,
+		beforeHooks:       l.beforeHooks,
+		afterHooks:        l.afterHooks,
+		processors:        l.processors,
+		redactor:          l.redactor,
+		encoder:           encoder,
+		name:              l.name,
+		reportCaller:      l.reportCaller,
+		ctx:               l.ctx,
+		multiline:         l.multiline,
+	}
+}
+
+// WithName returns a new Logger instance with the given name.
+// Names of nested loggers are joined with a dot, so
+// logger.WithName("api").WithName("auth") is named "api.auth".
+//
+// Parameters:
+//
+//	name: The logger name to append.
+func (l *Logger) WithName(name string) *Logger {
+	if l.name != "" {
+		name = l.name + "." + name
+	}
+
+	return &Logger{
+		level:             l.level,
+		writers:           l.writers,
+		prefixes:          l.prefixes,
+		errorHandler:      l.errorHandler,
+		concurrency:       l.concurrency,
+		fields:            l.fields.Copy(),
+		fieldsFormatter:   l.fieldsFormatter,
+		conditions:        l.conditions,
+		levelConditions:   l.levelConditions,
+		contextConditions: l.contextConditions,
+		exitFunc:          l.exitFunc,
+		exitCode:          l.exitCode,
+		exitTimeout:       l.exitTimeout,
+		exitHandlers:      l.exitHandlers,
+		repanic:           l.repanic,
+		beforeHooks:       l.beforeHooks,
+		afterHooks:        l.afterHooks,
+		processors:        l.processors,
+		redactor:          l.redactor,
+		encoder:           l.encoder,
+		name:              name,
+		reportCaller:      l.reportCaller,
+		ctx:               l.ctx,
+		multiline:         l.multiline,
+	}
+}
+
+// WithCaller returns a new Logger instance which records the file and line
+// of the logging call in Record.Caller.
+//
+// Parameters:
+//
+//	enabled: Whether caller reporting is enabled.
+func (l *Logger) WithCaller(enabled bool) *Logger {
+	return &Logger{
+		level:             l.level,
+		writers:           l.writers,
+		prefixes:          l.prefixes,
+		errorHandler:      l.errorHandler,
+		concurrency:       l.concurrency,
+		fields:            l.fields.Copy(),
+		fieldsFormatter:   l.fieldsFormatter,
+		conditions:        l.conditions,
+		levelConditions:   l.levelConditions,
+		contextConditions: l.contextConditions,
+		exitFunc:          l.exitFunc,
+		exitCode:          l.exitCode,
+		exitTimeout:       l.exitTimeout,
+		exitHandlers:      l.exitHandlers,
+		repanic:           l.repanic,
+		beforeHooks:       l.beforeHooks,
+		afterHooks:        l.afterHooks,
+		processors:        l.processors,
+		redactor:          l.redactor,
+		encoder:           l.encoder,
+		name:              l.name,
+		reportCaller:      enabled,
+		ctx:               l.ctx,
+		multiline:         l.multiline,
+	}
+}
@@ -0,0 +1,157 @@
+package balogan
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJSONEncoder_Encode(t *testing.T) {
+	record := &Record{
+		Time:    time.Date(2024, 12, 13, 15, 30, 45, 0, time.UTC),
+		Level:   InfoLevel,
+		Message: "User logged in",
+		Fields:  Fields{"user": "john", "attempt": 2},
+		Logger:  "api",
+		Caller:  "app/main.go:10",
+	}
+
+	got := (&JSONEncoder{}).Encode(record)
+	expected := `{"time":"2024-12-13T15:30:45Z","level":"INFO","msg":"User logged in","logger":"api","caller":"app/main.go:10","attempt":2,"user":"john"}`
+	if got != expected {
+		t.Errorf("Encode() =\n%s\nwant\n%s", got, expected)
+	}
+}
+
+func TestJSONEncoder_CustomKeys(t *testing.T) {
+	record := &Record{
+		Time:    time.Date(2024, 12, 13, 15, 30, 45, 0, time.UTC),
+		Level:   WarningLevel,
+		Message: "disk almost full",
+		Fields:  Fields{"disk": "/dev/sda1"},
+	}
+
+	encoder := &JSONEncoder{
+		TimeKey:        "@timestamp",
+		LevelKey:       "severity",
+		MessageKey:     "message",
+		FieldsKey:      "fields",
+		TimeFormat:     time.RFC3339,
+		LowercaseLevel: true,
+	}
+
+	got := encoder.Encode(record)
+	expected := `{"@timestamp":"2024-12-13T15:30:45Z","severity":"warning","message":"disk almost full","fields":{"disk":"/dev/sda1"}}`
+	if got != expected {
+		t.Errorf("Encode() =\n%s\nwant\n%s", got, expected)
+	}
+}
+
+func TestJSONEncoder_ValidSingleLine(t *testing.T) {
+	record := &Record{
+		Time:    time.Now(),
+		Level:   ErrorLevel,
+		Message: "line1\nline2 \"quoted\"",
+		Fields: Fields{
+			"msg":   "clashes with message key",
+			"err":   errors.New("boom"),
+			"ch":    make(chan int),
+			"multi": "a\nb",
+		},
+	}
+
+	got := (&JSONEncoder{}).Encode(record)
+	if strings.Contains(got, "\n") {
+		t.Errorf("Encoded line should not contain raw newlines: %q", got)
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal([]byte(got), &decoded); err != nil {
+		t.Fatalf("Encoded line is not valid JSON: %v\n%s", err, got)
+	}
+	if decoded["msg"] != record.Message {
+		t.Errorf("Message should not be overwritten by field, got %v", decoded["msg"])
+	}
+	if decoded["fields.msg"] != "clashes with message key" {
+		t.Errorf("Clashing field should be prefixed, got %v", decoded)
+	}
+	if decoded["err"] != "boom" {
+		t.Errorf("Errors should be encoded as their message, got %v", decoded["err"])
+	}
+}
+
+func TestLogger_WithEncoder(t *testing.T) {
+	mockWriter := &MockWriter{}
+	logger := New(InfoLevel, mockWriter, WithTag("ignored")).
+		WithEncoder(&JSONEncoder{}).
+		WithName("api").
+		WithName("auth").
+		WithField("user", "john")
+
+	logger.Info("User logged in")
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(mockWriter.Bytes(), &decoded); err != nil {
+		t.Fatalf("Output is not valid JSON: %v\n%s", err, mockWriter.String())
+	}
+	if decoded["level"] != "INFO" || decoded["msg"] != "User logged in" || decoded["user"] != "john" {
+		t.Errorf("Unexpected output: %v", decoded)
+	}
+	if decoded["logger"] != "api.auth" {
+		t.Errorf("Expected nested logger name, got %v", decoded["logger"])
+	}
+	if _, exists := decoded["caller"]; exists {
+		t.Error("Caller should be omitted unless enabled")
+	}
+
+	mockWriter.Reset()
+	logger.WithEncoder(nil).Info("plain")
+	if got := mockWriter.String(); got != "INFO ignored user=john plain" {
+		t.Errorf("nil encoder should restore text line, got %q", got)
+	}
+}
+
+func TestLogger_WithCaller(t *testing.T) {
+	mockWriter := &MockWriter{}
+	logger := New(InfoLevel, mockWriter).WithEncoder(&JSONEncoder{}).WithCaller(true)
+
+	logger.Infof("with %s", "caller")
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(mockWriter.Bytes(), &decoded); err != nil {
+		t.Fatalf("Output is not valid JSON: %v", err)
+	}
+	caller, _ := decoded["caller"].(string)
+	if !strings.Contains(caller, "/encoder_test.go:") {
+		t.Errorf("Expected caller in this test file, got %q", caller)
+	}
+}
+
+func TestNewFromConfig_Encoder(t *testing.T) {
+	logger := NewFromConfig(&BaloganConfig{
+		Level:        InfoLevel,
+		Encoder:      &JSONEncoder{},
+		Name:         "svc",
+		ReportCaller: true,
+	})
+
+	if _, ok := logger.encoder.(*JSONEncoder); !ok {
+		t.Error("Encoder not set from config")
+	}
+	if logger.name != "svc" || !logger.reportCaller {
+		t.Error("Name or ReportCaller not set from config")
+	}
+}
+
+func TestTextEncoder(t *testing.T) {
+	record := &Record{Level: WarningLevel, Message: "disk low", Prefixes: []string{"[db]"}, Fields: Fields{"free": "5%"}}
+
+	if got := (&TextEncoder{}).Encode(record); got != "WARNING [db] free=5% disk low" {
+		t.Errorf("Unexpected line %q", got)
+	}
+	if got := (&TextEncoder{FieldsFormatter: &JSONFormatter{}}).Encode(record); got != `WARNING [db] {"free":"5%"} disk low` {
+		t.Errorf("Unexpected line %q", got)
+	}
+}
@@ -0,0 +1,243 @@
+package balogan
+
+import (
+	"context"
+	"os"
+	"time"
+)
+
+// DefaultExitCode is the process exit code used by Fatal and Fatalf
+// unless another code is configured with WithExitCode.
+const DefaultExitCode = 1
+
+// DefaultExitTimeout is the time exit handlers are given to finish
+// before the process exits anyway.
+const DefaultExitTimeout = 5 * time.Second
+
+// ExitFunc terminates the program with the given exit code.
+// os.Exit is used by default. Tests can replace it to cover fatal paths
+// without stopping the test binary.
+type ExitFunc func(code int)
+
+// ExitHandler is a cleanup function which runs before a fatal exit.
+// The context is cancelled when the exit timeout expires.
+// Returned errors are passed to the logger's ErrorHandler.
+//
+// Example usage:
+//
+//	handler := func(ctx context.Context) error {
+//		return server.Shutdown(ctx)
+//	}
+//	logger = logger.WithExitHandler(handler)
+type ExitHandler func(ctx context.Context) error
+
+// Exit runs the registered exit handlers, closes all writers and then
+// terminates the program with the given exit code using the configured ExitFunc.
+//
+// Exit handlers run in registration order and share a single timeout.
+// If they do not finish in time, writers are closed and the program exits anyway.
+//
+// Parameters:
+//
+//	code: The process exit code.
+func (l *Logger) Exit(code int) {
+	l.runExitHandlers()
+
+	if err := l.Close(); err != nil {
+		l.errorHandler.Handle(err)
+	}
+
+	exit := l.exitFunc
+	if exit == nil {
+		exit = os.Exit
+	}
+	exit(code)
+}
+
+// WithExitHandler returns a new Logger instance with the exit handler registered.
+// Handlers run before writers are closed on Fatal, Fatalf and Exit.
+//
+// Parameters:
+//
+//	handler: The cleanup function to run before exit.
+//
+// Example:
+//
+//	logger.WithExitHandler(func(ctx context.Context) error {
+//		return db.Close()
+//	}).Fatal("Unrecoverable error")
+func (l *Logger) WithExitHandler(handler ExitHandler) *Logger {
+	exitHandlers := make([]ExitHandler, len(l.exitHandlers))
+	copy(exitHandlers, l.exitHandlers)
+	exitHandlers = append(exitHandlers, handler)
+
+	return &Logger{
+		level:             l.level,
+		writers:           l.writers,
+		prefixes:          l.prefixes,
+		errorHandler:      l.errorHandler,
+		concurrency:       l.concurrency,
+		fields:            l.fields.Copy(),
+		fieldsFormatter:   l.fieldsFormatter,
+		conditions:        l.conditions,
+		levelConditions:   l.levelConditions,
+		contextConditions: l.contextConditions,
+		exitFunc:          l.exitFunc,
+		exitCode:          l.exitCode,
+		exitTimeout:       l.exitTimeout,
+		exitHandlers:      exitHandlers,
+		repanic:           l.repanic,
+		beforeHooks:       l.beforeHooks,
+		afterHooks:        l.afterHooks,
+		processors:        l.processors,
+		redactor:          l.redactor,
+		encoder:           l.encoder,
+		name:              l.name,
+		reportCaller:      l.reportCaller,
+		ctx:               l.ctx,
+		multiline:         l.multiline,
+	}
+}
+
+// WithExitFunc returns a new Logger instance which terminates the program
+// with the given function instead of os.Exit.
+//
+// Parameters:
+//
+//	exitFunc: The function called with the exit code.
+//
+// Example:
+//
+//	var code int
+//	logger.WithExitFunc(func(c int) { code = c }).Fatal("Boom")
+func (l *Logger) WithExitFunc(exitFunc ExitFunc) *Logger {
+	return &Logger{
+		level:             l.level,
+		writers:           l.writers,
+		prefixes:          l.prefixes,
+		errorHandler:      l.errorHandler,
+		concurrency:       l.concurrency,
+		fields:            l.fields.Copy(),
+		fieldsFormatter:   l.fieldsFormatter,
+		conditions:        l.conditions,
+		levelConditions:   l.levelConditions,
+		contextConditions: l.contextConditions,
+		exitFunc:          exitFunc,
+		exitCode:          l.exitCode,
+		exitTimeout:       l.exitTimeout,
+		exitHandlers:      l.exitHandlers,
+		repanic:           l.repanic,
+		beforeHooks:       l.beforeHooks,
+		afterHooks:        l.afterHooks,
+		processors:        l.processors,
+		redactor:          l.redactor,
+		encoder:           l.encoder,
+		name:              l.name,
+		reportCaller:      l.reportCaller,
+		ctx:               l.ctx,
+		multiline:         l.multiline,
+	}
+}
+
+// WithExitCode returns a new Logger instance which exits with the given code
+// on Fatal and Fatalf.
+//
+// Parameters:
+//
+//	code: The process exit code.
+func (l *Logger) WithExitCode(code int) *Logger {
+	return &Logger{
+		level:             l.level,
+		writers:           l.writers,
+		prefixes:          l.prefixes,
+		errorHandler:      l.errorHandler,
+		concurrency:       l.concurrency,
+		fields:            l.fields.Copy(),
+		fieldsFormatter:   l.fieldsFormatter,
+		conditions:        l.conditions,
+		levelConditions:   l.levelConditions,
+		contextConditions: l.contextConditions,
+		exitFunc:          l.exitFunc,
+		exitCode:          code,
+		exitTimeout:       l.exitTimeout,
+		exitHandlers:      l.exitHandlers,
+		repanic:           l.repanic,
+		beforeHooks:       l.beforeHooks,
+		afterHooks:        l.afterHooks,
+		processors:        l.processors,
+		redactor:          l.redactor,
+		encoder:           l.encoder,
+		name:              l.name,
+		reportCaller:      l.reportCaller,
+		ctx:               l.ctx,
+		multiline:         l.multiline,
+	}
+}
+
+// WithExitTimeout returns a new Logger instance which gives exit handlers
+// at most the given duration to finish.
+//
+// Parameters:
+//
+//	timeout: The maximum time for all exit handlers together.
+func (l *Logger) WithExitTimeout(timeout time.Duration) *Logger {
+	return &Logger{
+		level:             l.level,
+		writers:           l.writers,
+		prefixes:          l.prefixes,
+		errorHandler:      l.errorHandler,
+		concurrency:       l.concurrency,
+		fields:            l.fields.Copy(),
+		fieldsFormatter:   l.fieldsFormatter,
+		conditions:        l.conditions,
+		levelConditions:   l.levelConditions,
+		contextConditions: l.contextConditions,
+		exitFunc:          l.exitFunc,
+		exitCode:          l.exitCode,
+		exitTimeout:       timeout,
+		exitHandlers:      l.exitHandlers,
+		repanic:           l.repanic,
+		beforeHooks:       l.beforeHooks,
+		afterHooks:        l.afterHooks,
+		processors:        l.processors,
+		redactor:          l.redactor,
+		encoder:           l.encoder,
+		name:              l.name,
+		reportCaller:      l.reportCaller,
+		ctx:               l.ctx,
+		multiline:         l.multiline,
+	}
+}
+
+func (l *Logger) runExitHandlers() {
+	if len(l.exitHandlers) == 0 {
+		return
+	}
+
+	timeout := l.exitTimeout
+	if timeout <= 0 {
+		timeout = DefaultExitTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for _, handler := range l.exitHandlers {
+			if ctx.Err() != nil {
+				return
+			}
+			if err := handler(ctx); err != nil {
+				l.errorHandler.Handle(err)
+			}
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		l.errorHandler.Handle(ctx.Err())
+	}
+}
@@ -0,0 +1,155 @@
+package balogan
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogger_FatalUsesExitFunc(t *testing.T) {
+	mockWriter := &MockWriter{}
+	exitCode := -1
+
+	logger := New(InfoLevel, mockWriter).WithExitFunc(func(code int) {
+		exitCode = code
+	})
+
+	logger.Fatal("fatal message")
+
+	if exitCode != DefaultExitCode {
+		t.Errorf("Expected exit code %d, got %d", DefaultExitCode, exitCode)
+	}
+	if !strings.Contains(mockWriter.String(), "FATAL fatal message") {
+		t.Errorf("Fatal message not written: %q", mockWriter.String())
+	}
+	if !mockWriter.IsClosed() {
+		t.Error("Writers should be closed before exit")
+	}
+}
+
+func TestLogger_FatalfWithExitCode(t *testing.T) {
+	exitCode := -1
+
+	logger := New(InfoLevel, &MockWriter{}).
+		WithExitCode(3).
+		WithExitFunc(func(code int) { exitCode = code })
+
+	logger.Fatalf("fatal %d", 42)
+
+	if exitCode != 3 {
+		t.Errorf("Expected exit code 3, got %d", exitCode)
+	}
+}
+
+func TestLogger_ExitHandlersRunInOrder(t *testing.T) {
+	mockWriter := &MockWriter{}
+	var calls []string
+
+	logger := New(InfoLevel, mockWriter).
+		WithExitFunc(func(int) {
+			calls = append(calls, "exit")
+		}).
+		WithExitHandler(func(ctx context.Context) error {
+			calls = append(calls, "first")
+			return nil
+		}).
+		WithExitHandler(func(ctx context.Context) error {
+			if mockWriter.IsClosed() {
+				t.Error("Writers should not be closed before exit handlers run")
+			}
+			calls = append(calls, "second")
+			return nil
+		})
+
+	logger.Fatal("fatal")
+
+	expected := "first,second,exit"
+	if got := strings.Join(calls, ","); got != expected {
+		t.Errorf("Expected call order %q, got %q", expected, got)
+	}
+}
+
+func TestLogger_ExitHandlerErrors(t *testing.T) {
+	handlerErr := errors.New("cleanup failed")
+	var handled []error
+
+	logger := New(InfoLevel, &MockWriter{}).
+		WithExitFunc(func(int) {}).
+		WithExitHandler(func(ctx context.Context) error {
+			return handlerErr
+		})
+	logger.errorHandler = &MockErrorHandler{
+		HandleFunc: func(err error) { handled = append(handled, err) },
+	}
+
+	logger.Exit(2)
+
+	if len(handled) != 1 || !errors.Is(handled[0], handlerErr) {
+		t.Errorf("Expected handler error to be reported, got %v", handled)
+	}
+}
+
+func TestLogger_ExitHandlerTimeout(t *testing.T) {
+	exited := false
+	release := make(chan struct{})
+	defer close(release)
+
+	logger := New(InfoLevel, &MockWriter{}).
+		WithExitTimeout(20 * time.Millisecond).
+		WithExitFunc(func(int) { exited = true }).
+		WithExitHandler(func(ctx context.Context) error {
+			<-release
+			return nil
+		})
+
+	start := time.Now()
+	logger.Exit(1)
+
+	if !exited {
+		t.Error("Exit func should be called after timeout")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Exit took too long: %v", elapsed)
+	}
+}
+
+func TestLogger_ExitHandlersNotShared(t *testing.T) {
+	parent := New(InfoLevel, &MockWriter{})
+	child := parent.WithExitHandler(func(ctx context.Context) error { return nil })
+
+	if len(parent.exitHandlers) != 0 {
+		t.Errorf("Parent should have no exit handlers, got %d", len(parent.exitHandlers))
+	}
+	if len(child.exitHandlers) != 1 {
+		t.Errorf("Child should have 1 exit handler, got %d", len(child.exitHandlers))
+	}
+}
+
+func TestNewFromConfig_ExitDefaults(t *testing.T) {
+	logger := NewFromConfig(&BaloganConfig{Level: InfoLevel, Writers: []LogWriter{&MockWriter{}}})
+
+	if logger.exitCode != DefaultExitCode {
+		t.Errorf("Expected default exit code %d, got %d", DefaultExitCode, logger.exitCode)
+	}
+	if logger.exitTimeout != DefaultExitTimeout {
+		t.Errorf("Expected default exit timeout %v, got %v", DefaultExitTimeout, logger.exitTimeout)
+	}
+	if logger.exitFunc == nil {
+		t.Error("Expected default exit func")
+	}
+
+	var code int
+	logger = NewFromConfig(&BaloganConfig{
+		Level:    InfoLevel,
+		Writers:  []LogWriter{&MockWriter{}},
+		ExitCode: 7,
+		ExitFunc: func(c int) { code = c },
+	})
+	logger.Fatal("configured exit")
+
+	if code != 7 {
+		t.Errorf("Expected exit code 7, got %d", code)
+	}
+}
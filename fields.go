@@ -63,6 +63,7 @@ func (f *KeyValueFormatter) Format(fields Fields) string {
 }
 
 // LogfmtFormatter formats fields in logfmt style (key=value with proper escaping).
+// Values are quoted and escaped the same way as by LogfmtEncoder.
 type LogfmtFormatter struct{}
 
 func (f *LogfmtFormatter) Format(fields Fields) string {
@@ -79,11 +80,7 @@ func (f *LogfmtFormatter) Format(fields Fields) string {
 	pairs := make([]string, 0, len(fields))
 	for _, k := range keys {
 		v := fields[k]
-		valueStr := fmt.Sprintf("%v", v)
-		if strings.Contains(valueStr, " ") || strings.Contains(valueStr, "=") {
-			valueStr = fmt.Sprintf(`"%s"`, strings.ReplaceAll(valueStr, `"`, `\"`))
-		}
-		pairs = append(pairs, fmt.Sprintf("%s=%s", k, valueStr))
+		pairs = append(pairs, logfmtKey(k)+"="+logfmtValue(logfmtString(v)))
 	}
 
 	return strings.Join(pairs, " ")
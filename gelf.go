@@ -0,0 +1,281 @@
+package balogan
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Default GELF settings.
+const (
+	// DefaultGELFChunkSize is the maximum UDP datagram size, including the chunk header.
+	DefaultGELFChunkSize = 1420
+	// DefaultGELFDialTimeout is the timeout for establishing TCP connections.
+	DefaultGELFDialTimeout = 5 * time.Second
+
+	gelfVersion        = "1.1"
+	gelfMaxChunks      = 128
+	gelfChunkHeaderLen = 12
+)
+
+// ErrGELFMessageTooLarge is returned when a UDP message needs more than 128 chunks.
+var ErrGELFMessageTooLarge = errors.New("gelf: message too large")
+
+var (
+	gelfChunkMagic = []byte{0x1e, 0x0f}
+	gelfInvalidKey = regexp.MustCompile(`[^\w.\-]`)
+)
+
+// GELFConfig configures a GELFWriter.
+type GELFConfig struct {
+	// Network is "udp" (default) or "tcp".
+	Network string
+	// Address is the Graylog input address, for example "graylog:12201".
+	Address string
+	// Host is reported in the "host" field. os.Hostname is used when empty.
+	Host string
+
+	// Compress gzips UDP messages. GELF over TCP does not support compression.
+	Compress bool
+	// ChunkSize is the maximum UDP datagram size. DefaultGELFChunkSize is used when zero.
+	ChunkSize int
+
+	// DialTimeout limits TCP connection setup. DefaultGELFDialTimeout is used when zero.
+	DialTimeout time.Duration
+	// WriteTimeout sets a deadline for every write. No deadline is set when zero.
+	WriteTimeout time.Duration
+}
+
+// GELFWriter sends records to Graylog as GELF 1.1 messages.
+//
+// Over UDP, messages larger than ChunkSize are split into GELF chunks and
+// may be gzipped. Over TCP, messages are framed with a null byte and the
+// connection is re-established once if a write fails.
+//
+// The record's message becomes "short_message" (its first line, with the whole
+// message in "full_message" when it spans several lines), the level is mapped
+// to a syslog severity, and fields are sent as "_"-prefixed additional fields.
+type GELFWriter struct {
+	mu   sync.Mutex
+	cfg  GELFConfig
+	conn net.Conn
+}
+
+// NewGELFWriter creates a new GELFWriter and connects to the configured address.
+func NewGELFWriter(cfg *GELFConfig) (*GELFWriter, error) {
+	if cfg == nil || cfg.Address == "" {
+		return nil, errors.New("gelf: address is required")
+	}
+
+	c := *cfg
+	if c.Network == "" {
+		c.Network = "udp"
+	}
+	if c.Network != "udp" && c.Network != "tcp" {
+		return nil, fmt.Errorf("gelf: unsupported network %q", c.Network)
+	}
+	if c.Host == "" {
+		c.Host, _ = os.Hostname()
+	}
+	if c.ChunkSize <= gelfChunkHeaderLen {
+		c.ChunkSize = DefaultGELFChunkSize
+	}
+	if c.DialTimeout <= 0 {
+		c.DialTimeout = DefaultGELFDialTimeout
+	}
+
+	w := &GELFWriter{cfg: c}
+	if err := w.dial(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Write sends bytes as the short message of an INFO level GELF message.
+func (w *GELFWriter) Write(bytes []byte) (int, error) {
+	record := &Record{Time: time.Now(), Level: InfoLevel, Message: string(bytes)}
+	if err := w.WriteRecord(record, bytes); err != nil {
+		return 0, err
+	}
+	return len(bytes), nil
+}
+
+// WriteRecord sends the record as a GELF message.
+func (w *GELFWriter) WriteRecord(record *Record, line []byte) error {
+	payload, err := json.Marshal(w.message(record, line))
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.cfg.Network == "tcp" {
+		return w.sendTCP(append(payload, 0))
+	}
+	return w.sendUDP(payload)
+}
+
+func (w *GELFWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn == nil {
+		return os.ErrClosed
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+func (w *GELFWriter) message(record *Record, line []byte) map[string]interface{} {
+	message := record.Message
+	if message == "" {
+		message = string(line)
+	}
+	if message == "" {
+		message = "-"
+	}
+
+	msg := map[string]interface{}{
+		"version":   gelfVersion,
+		"host":      w.cfg.Host,
+		"timestamp": float64(record.Time.UnixMilli()) / 1000,
+		"level":     record.Level.SyslogSeverity(),
+	}
+
+	if short, _, multiline := strings.Cut(message, "\n"); multiline {
+		msg["short_message"] = short
+		msg["full_message"] = message
+	} else {
+		msg["short_message"] = message
+	}
+
+	if record.Logger != "" {
+		msg["_logger"] = record.Logger
+	}
+	if record.Caller != "" {
+		msg["_caller"] = record.Caller
+	}
+	for k, v := range record.Fields {
+		msg[gelfFieldKey(k)] = gelfFieldValue(v)
+	}
+
+	return msg
+}
+
+func (w *GELFWriter) dial() error {
+	conn, err := net.DialTimeout(w.cfg.Network, w.cfg.Address, w.cfg.DialTimeout)
+	if err != nil {
+		return err
+	}
+	w.conn = conn
+	return nil
+}
+
+func (w *GELFWriter) setDeadline() {
+	if w.cfg.WriteTimeout > 0 {
+		_ = w.conn.SetWriteDeadline(time.Now().Add(w.cfg.WriteTimeout))
+	}
+}
+
+func (w *GELFWriter) sendTCP(frame []byte) error {
+	if w.conn == nil {
+		return os.ErrClosed
+	}
+
+	w.setDeadline()
+	if _, err := w.conn.Write(frame); err == nil {
+		return nil
+	}
+
+	// The connection may have been closed by Graylog, reconnect once.
+	_ = w.conn.Close()
+	if err := w.dial(); err != nil {
+		return err
+	}
+	w.setDeadline()
+	_, err := w.conn.Write(frame)
+	return err
+}
+
+func (w *GELFWriter) sendUDP(payload []byte) error {
+	if w.conn == nil {
+		return os.ErrClosed
+	}
+
+	if w.cfg.Compress {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(payload); err != nil {
+			return err
+		}
+		if err := zw.Close(); err != nil {
+			return err
+		}
+		payload = buf.Bytes()
+	}
+
+	if len(payload) <= w.cfg.ChunkSize {
+		w.setDeadline()
+		_, err := w.conn.Write(payload)
+		return err
+	}
+
+	dataSize := w.cfg.ChunkSize - gelfChunkHeaderLen
+	count := (len(payload) + dataSize - 1) / dataSize
+	if count > gelfMaxChunks {
+		return ErrGELFMessageTooLarge
+	}
+
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return err
+	}
+
+	chunk := make([]byte, 0, w.cfg.ChunkSize)
+	for i := 0; i < count; i++ {
+		end := min((i+1)*dataSize, len(payload))
+
+		chunk = append(chunk[:0], gelfChunkMagic...)
+		chunk = append(chunk, id...)
+		chunk = append(chunk, byte(i), byte(count))
+		chunk = append(chunk, payload[i*dataSize:end]...)
+
+		w.setDeadline()
+		if _, err := w.conn.Write(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// gelfFieldKey converts a field key into a valid GELF additional field name.
+func gelfFieldKey(key string) string {
+	key = gelfInvalidKey.ReplaceAllString(key, "_")
+	if key == "id" || key == "" {
+		// "_id" is reserved by GELF.
+		key = "_" + key
+	}
+	return "_" + key
+}
+
+// gelfFieldValue converts a field value into a GELF value.
+// GELF only supports strings and numbers, everything else is sent as a string.
+func gelfFieldValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, string:
+		return v
+	default:
+		return logfmtString(v)
+	}
+}
@@ -0,0 +1,225 @@
+package balogan
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func listenUDP(t *testing.T) net.PacketConn {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("ListenPacket() error = %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func readDatagram(t *testing.T, conn net.PacketConn) []byte {
+	t.Helper()
+	buf := make([]byte, 65536)
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("ReadFrom() error = %v", err)
+	}
+	return buf[:n]
+}
+
+func TestGELFWriter_UDP(t *testing.T) {
+	listener := listenUDP(t)
+
+	w, err := NewGELFWriter(&GELFConfig{Address: listener.LocalAddr().String(), Host: "test-host"})
+	if err != nil {
+		t.Fatalf("NewGELFWriter() error = %v", err)
+	}
+	defer w.Close()
+
+	var _ RecordWriter = w
+
+	logger := New(InfoLevel, w).WithName("api").WithFields(Fields{
+		"user":    "john",
+		"count":   3,
+		"id":      "reserved",
+		"bad key": true,
+	})
+	logger.Warning("Disk almost full")
+
+	var msg map[string]interface{}
+	if err := json.Unmarshal(readDatagram(t, listener), &msg); err != nil {
+		t.Fatalf("Invalid GELF JSON: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"version":       "1.1",
+		"host":          "test-host",
+		"short_message": "Disk almost full",
+		"level":         float64(4),
+		"_logger":       "api",
+		"_user":         "john",
+		"_count":        float64(3),
+		"__id":          "reserved",
+		"_bad_key":      "true",
+	}
+	for k, v := range expected {
+		if msg[k] != v {
+			t.Errorf("GELF field %q = %v, want %v", k, msg[k], v)
+		}
+	}
+	if _, ok := msg["timestamp"].(float64); !ok {
+		t.Errorf("GELF timestamp should be a number, got %v", msg["timestamp"])
+	}
+	if _, exists := msg["full_message"]; exists {
+		t.Error("full_message should be omitted for single line messages")
+	}
+}
+
+func TestGELFWriter_UDPChunkedCompressed(t *testing.T) {
+	listener := listenUDP(t)
+
+	w, err := NewGELFWriter(&GELFConfig{
+		Address:   listener.LocalAddr().String(),
+		Compress:  true,
+		ChunkSize: 64,
+	})
+	if err != nil {
+		t.Fatalf("NewGELFWriter() error = %v", err)
+	}
+	defer w.Close()
+
+	var long strings.Builder
+	for long.Len() < 2000 {
+		long.WriteString(time.Now().String())
+	}
+	message := "first line\n" + long.String()
+
+	if err := w.WriteRecord(&Record{Time: time.Now(), Level: ErrorLevel, Message: message}, nil); err != nil {
+		t.Fatalf("WriteRecord() error = %v", err)
+	}
+
+	var chunks [][]byte
+	var count int
+	for {
+		chunk := readDatagram(t, listener)
+		if !bytes.Equal(chunk[:2], gelfChunkMagic) {
+			t.Fatalf("Expected chunk magic bytes, got %x", chunk[:2])
+		}
+		if len(chunk) > 64 {
+			t.Errorf("Chunk exceeds chunk size: %d", len(chunk))
+		}
+		count = int(chunk[11])
+		if int(chunk[10]) != len(chunks) {
+			t.Fatalf("Unexpected chunk sequence number %d", chunk[10])
+		}
+		chunks = append(chunks, chunk[gelfChunkHeaderLen:])
+		if len(chunks) == count {
+			break
+		}
+	}
+	if count < 2 {
+		t.Fatalf("Expected message to be chunked, got %d chunks", count)
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(bytes.Join(chunks, nil)))
+	if err != nil {
+		t.Fatalf("gzip.NewReader() error = %v", err)
+	}
+	payload, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("Reading gzip payload error = %v", err)
+	}
+
+	var msg map[string]interface{}
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		t.Fatalf("Invalid GELF JSON: %v", err)
+	}
+	if msg["short_message"] != "first line" || msg["full_message"] != message {
+		t.Errorf("Unexpected messages: short=%v", msg["short_message"])
+	}
+	if msg["level"] != float64(3) {
+		t.Errorf("Expected level 3, got %v", msg["level"])
+	}
+}
+
+func TestGELFWriter_UDPTooLarge(t *testing.T) {
+	listener := listenUDP(t)
+
+	w, err := NewGELFWriter(&GELFConfig{Address: listener.LocalAddr().String(), ChunkSize: 20})
+	if err != nil {
+		t.Fatalf("NewGELFWriter() error = %v", err)
+	}
+	defer w.Close()
+
+	if _, err := w.Write([]byte(strings.Repeat("x", 2000))); err != ErrGELFMessageTooLarge {
+		t.Errorf("Expected ErrGELFMessageTooLarge, got %v", err)
+	}
+}
+
+func TestGELFWriter_TCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	defer listener.Close()
+
+	frames := make(chan []byte, 2)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		for {
+			frame, err := reader.ReadBytes(0)
+			if err != nil {
+				return
+			}
+			frames <- frame
+		}
+	}()
+
+	w, err := NewGELFWriter(&GELFConfig{Network: "tcp", Address: listener.Addr().String()})
+	if err != nil {
+		t.Fatalf("NewGELFWriter() error = %v", err)
+	}
+	defer w.Close()
+
+	logger := New(InfoLevel, w)
+	logger.Info("first")
+	logger.Error("second")
+
+	for _, expected := range []string{"first", "second"} {
+		select {
+		case frame := <-frames:
+			if frame[len(frame)-1] != 0 {
+				t.Fatal("Frame should be terminated by a null byte")
+			}
+			var msg map[string]interface{}
+			if err := json.Unmarshal(frame[:len(frame)-1], &msg); err != nil {
+				t.Fatalf("Invalid GELF JSON: %v", err)
+			}
+			if msg["short_message"] != expected {
+				t.Errorf("Expected %q, got %v", expected, msg["short_message"])
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timed out waiting for GELF frame")
+		}
+	}
+}
+
+func TestNewGELFWriter_InvalidConfig(t *testing.T) {
+	if _, err := NewGELFWriter(nil); err == nil {
+		t.Error("Expected error for nil config")
+	}
+	if _, err := NewGELFWriter(&GELFConfig{Network: "unix", Address: "/tmp/x"}); err == nil {
+		t.Error("Expected error for unsupported network")
+	}
+}
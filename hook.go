@@ -0,0 +1,189 @@
+package balogan
+
+// AllLevels contains every log level in ascending order.
+// Hooks returning AllLevels fire for any record.
+var AllLevels = []LogLevel{
+	TraceLevel,
+	DebugLevel,
+	InfoLevel,
+	WarningLevel,
+	ErrorLevel,
+	FatalLevel,
+	PanicLevel,
+}
+
+// Hook is a side effect which runs for every record of the levels it declares.
+// Hooks run outside of the logger's write lock, so a slow hook does not block writers
+// of other goroutines. Errors returned by Fire are passed to the logger's ErrorHandler.
+//
+// Example usage:
+//
+//	type errorCounter struct{ count atomic.Int64 }
+//
+//	func (h *errorCounter) Levels() []LogLevel { return []LogLevel{ErrorLevel, FatalLevel} }
+//	func (h *errorCounter) Fire(*Record) error { h.count.Add(1); return nil }
+type Hook interface {
+	// Levels returns the levels for which the hook fires.
+	Levels() []LogLevel
+	// Fire is called with the record being logged.
+	Fire(record *Record) error
+}
+
+// HookStage determines when a hook runs relative to the logger's writers.
+type HookStage int
+
+const (
+	// BeforeWrite hooks run before the record is formatted and written.
+	// Changes they make to the record are visible in the output.
+	BeforeWrite HookStage = iota
+	// AfterWrite hooks run after all writers have received the record.
+	AfterWrite
+)
+
+// HookFunc adapts an ordinary function to be used as a Hook with NewHook.
+type HookFunc func(record *Record) error
+
+type funcHook struct {
+	levels []LogLevel
+	fire   HookFunc
+}
+
+func (h *funcHook) Levels() []LogLevel {
+	return h.levels
+}
+
+func (h *funcHook) Fire(record *Record) error {
+	return h.fire(record)
+}
+
+// NewHook creates a Hook from a function.
+// If no levels are provided, the hook fires for all levels.
+//
+// Parameters:
+//
+//	fire: The function called for every matching record.
+//	levels: The levels for which the hook fires.
+//
+// Example:
+//
+//	pager := NewHook(func(r *Record) error {
+//		return pagerDuty.Trigger(r.Message)
+//	}, FatalLevel, PanicLevel)
+func NewHook(fire HookFunc, levels ...LogLevel) Hook {
+	if len(levels) == 0 {
+		levels = AllLevels
+	}
+
+	return &funcHook{levels: levels, fire: fire}
+}
+
+// WithHook returns a new Logger instance with the hook registered at the given stage.
+// Hooks are inherited by loggers derived from the returned one.
+//
+// Parameters:
+//
+//	stage: Whether the hook runs before or after the writers.
+//	hook: The hook to register.
+//
+// Example:
+//
+//	logger.WithHook(AfterWrite, errorCounter).Error("Counted error")
+func (l *Logger) WithHook(stage HookStage, hook Hook) *Logger {
+	beforeHooks := l.beforeHooks
+	afterHooks := l.afterHooks
+
+	if stage == BeforeWrite {
+		beforeHooks = make([]Hook, len(l.beforeHooks))
+		copy(beforeHooks, l.beforeHooks)
+		beforeHooks = append(beforeHooks, hook)
+	} else {
+		afterHooks = make([]Hook, len(l.afterHooks))
+		copy(afterHooks, l.afterHooks)
+		afterHooks = append(afterHooks, hook)
+	}
+
+	return &Logger{
+		level:             l.level,
+		writers:           l.writers,
+		prefixes:          l.prefixes,
+		errorHandler:      l.errorHandler,
+		concurrency:       l.concurrency,
+		fields:            l.fields.Copy(),
+		fieldsFormatter:   l.fieldsFormatter,
+		conditions:        l.conditions,
+		levelConditions:   l.levelConditions,
+		contextConditions: l.contextConditions,
+		exitFunc:          l.exitFunc,
+		exitCode:          l.exitCode,
+		exitTimeout:       l.exitTimeout,
+		exitHandlers:      l.exitHandlers,
+		repanic:           l.repanic,
+		beforeHooks:       beforeHooks,
+		afterHooks:        afterHooks,
+		processors:        l.processors,
+		redactor:          l.redactor,
+		encoder:           l.encoder,
+		name:              l.name,
+		reportCaller:      l.reportCaller,
+		ctx:               l.ctx,
+		multiline:         l.multiline,
+	}
+}
+
+// WithErrorHandler returns a new Logger instance which reports writer
+// and hook errors to the given handler.
+//
+// Parameters:
+//
+//	handler: The ErrorHandler receiving errors.
+//
+// Example:
+//
+//	logger.WithErrorHandler(ErrorHandlerFunc(func(err error) {
+//		fmt.Fprintln(os.Stderr, "log error:", err)
+//	}))
+func (l *Logger) WithErrorHandler(handler ErrorHandler) *Logger {
+	return &Logger{
+		level:             l.level,
+		writers:           l.writers,
+		prefixes:          l.prefixes,
+		errorHandler:      handler,
+		concurrency:       l.concurrency,
+		fields:            l.fields.Copy(),
+		fieldsFormatter:   l.fieldsFormatter,
+		conditions:        l.conditions,
+		levelConditions:   l.levelConditions,
+		contextConditions: l.contextConditions,
+		exitFunc:          l.exitFunc,
+		exitCode:          l.exitCode,
+		exitTimeout:       l.exitTimeout,
+		exitHandlers:      l.exitHandlers,
+		repanic:           l.repanic,
+		beforeHooks:       l.beforeHooks,
+		afterHooks:        l.afterHooks,
+		processors:        l.processors,
+		redactor:          l.redactor,
+		encoder:           l.encoder,
+		name:              l.name,
+		reportCaller:      l.reportCaller,
+		ctx:               l.ctx,
+		multiline:         l.multiline,
+	}
+}
+
+// fireHooks calls every hook registered for the record's level.
+// It must not be called while holding the logger's mutex.
+func (l *Logger) fireHooks(hooks []Hook, record *Record) {
+	for _, hook := range hooks {
+		for _, level := range hook.Levels() {
+			if level != record.Level {
+				continue
+			}
+
+			if err := hook.Fire(record); err != nil {
+				l.errorHandler.Handle(err)
+			}
+			break
+		}
+	}
+}
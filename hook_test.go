@@ -0,0 +1,142 @@
+package balogan
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNewHook_DefaultLevels(t *testing.T) {
+	hook := NewHook(func(*Record) error { return nil })
+	if len(hook.Levels()) != len(AllLevels) {
+		t.Errorf("Expected hook for all %d levels, got %d", len(AllLevels), len(hook.Levels()))
+	}
+
+	hook = NewHook(func(*Record) error { return nil }, ErrorLevel)
+	if len(hook.Levels()) != 1 || hook.Levels()[0] != ErrorLevel {
+		t.Errorf("Expected hook for ERROR only, got %v", hook.Levels())
+	}
+}
+
+func TestLogger_HookLevels(t *testing.T) {
+	var fired []LogLevel
+	hook := NewHook(func(r *Record) error {
+		fired = append(fired, r.Level)
+		return nil
+	}, ErrorLevel, WarningLevel)
+
+	logger := New(DebugLevel, &MockWriter{}).WithHook(AfterWrite, hook)
+	logger.Debug("debug")
+	logger.Info("info")
+	logger.Warning("warning")
+	logger.Error("error")
+
+	if len(fired) != 2 || fired[0] != WarningLevel || fired[1] != ErrorLevel {
+		t.Errorf("Expected hook for WARNING and ERROR, got %v", fired)
+	}
+}
+
+func TestLogger_BeforeWriteHookModifiesRecord(t *testing.T) {
+	mockWriter := &MockWriter{}
+	tagger := NewHook(func(r *Record) error {
+		r.Fields["tagged"] = true
+		return nil
+	})
+
+	logger := New(InfoLevel, mockWriter).WithHook(BeforeWrite, tagger)
+	logger.Info("tag me")
+
+	output := mockWriter.String()
+	if !strings.Contains(output, "tagged=true") {
+		t.Errorf("Before hook changes should be written, got %q", output)
+	}
+	if _, exists := logger.fields["tagged"]; exists {
+		t.Error("Hook should not modify the logger's own fields")
+	}
+}
+
+func TestLogger_HookStages(t *testing.T) {
+	mockWriter := &MockWriter{}
+	var seen []string
+
+	before := NewHook(func(r *Record) error {
+		seen = append(seen, "before:"+mockWriter.String())
+		return nil
+	})
+	after := NewHook(func(r *Record) error {
+		seen = append(seen, "after:"+mockWriter.String())
+		return nil
+	})
+
+	logger := New(InfoLevel, mockWriter).
+		WithHook(AfterWrite, after).
+		WithHook(BeforeWrite, before)
+	logger.Info("message")
+
+	if len(seen) != 2 {
+		t.Fatalf("Expected 2 hook calls, got %d", len(seen))
+	}
+	if seen[0] != "before:" {
+		t.Errorf("Before hook should run before writers, got %q", seen[0])
+	}
+	if seen[1] != "after:INFO message" {
+		t.Errorf("After hook should run after writers, got %q", seen[1])
+	}
+}
+
+func TestLogger_HookRunsOutsideMutex(t *testing.T) {
+	var logger *Logger
+	hook := NewHook(func(r *Record) error {
+		if !logger.mutex.TryLock() {
+			t.Error("Hook should not run while the logger mutex is held")
+			return nil
+		}
+		logger.mutex.Unlock()
+		return nil
+	})
+
+	logger = New(InfoLevel, &MockWriter{}).WithHook(AfterWrite, hook).WithHook(BeforeWrite, hook)
+	logger.Info("message")
+}
+
+func TestLogger_HookErrorsToErrorHandler(t *testing.T) {
+	hookErr := errors.New("hook failed")
+	var handled []error
+
+	logger := New(InfoLevel, &MockWriter{}).
+		WithErrorHandler(ErrorHandlerFunc(func(err error) {
+			handled = append(handled, err)
+		})).
+		WithHook(AfterWrite, NewHook(func(*Record) error { return hookErr }))
+
+	logger.Info("message")
+
+	if len(handled) != 1 || !errors.Is(handled[0], hookErr) {
+		t.Errorf("Expected hook error to be handled, got %v", handled)
+	}
+}
+
+func TestLogger_HooksNotShared(t *testing.T) {
+	parent := New(InfoLevel, &MockWriter{})
+	child := parent.WithHook(BeforeWrite, NewHook(func(*Record) error { return nil }))
+
+	if len(parent.beforeHooks) != 0 {
+		t.Errorf("Parent should have no hooks, got %d", len(parent.beforeHooks))
+	}
+	if len(child.WithField("k", "v").beforeHooks) != 1 {
+		t.Error("Derived loggers should inherit hooks")
+	}
+}
+
+func TestNewFromConfig_ErrorHandler(t *testing.T) {
+	var handled int
+	logger := NewFromConfig(&BaloganConfig{
+		Level:        InfoLevel,
+		ErrorHandler: ErrorHandlerFunc(func(error) { handled++ }),
+	})
+
+	logger.errorHandler.Handle(errors.New("test"))
+	if handled != 1 {
+		t.Errorf("Expected config error handler to be used, got %d calls", handled)
+	}
+}
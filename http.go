@@ -0,0 +1,280 @@
+package balogan
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// HTTPBodyFormat selects how an HTTPWriter renders request bodies.
+type HTTPBodyFormat int
+
+const (
+	// HTTPBodyJSON sends every record as a JSON object encoded with HTTPConfig.Encoder.
+	// Batches are sent as a JSON array.
+	HTTPBodyJSON HTTPBodyFormat = iota
+	// HTTPBodyRaw sends the formatted log line. Lines of a batch are separated by newlines.
+	HTTPBodyRaw
+)
+
+// Default HTTP writer settings.
+const (
+	// DefaultHTTPTimeout limits every request.
+	DefaultHTTPTimeout = 10 * time.Second
+	// DefaultHTTPMaxRetries is the number of retries of a failed request.
+	DefaultHTTPMaxRetries = 3
+	// DefaultHTTPRetryBackoff is the delay before the first retry.
+	DefaultHTTPRetryBackoff = 500 * time.Millisecond
+	// DefaultHTTPMaxBackoff caps the delay between retries.
+	DefaultHTTPMaxBackoff = 30 * time.Second
+	// DefaultHTTPFlushInterval is how often pending records are sent.
+	DefaultHTTPFlushInterval = time.Second
+)
+
+// HTTPTemplateData is passed to the body template of an HTTPWriter.
+type HTTPTemplateData struct {
+	// Records holds the records of the request, Lines their formatted lines.
+	Records []*Record
+	Lines   []string
+	// Record and Line are the first record and line, for requests with a single record.
+	Record *Record
+	Line   string
+}
+
+// HTTPConfig configures an HTTPWriter.
+type HTTPConfig struct {
+	// URL is the target of the requests.
+	URL string
+	// Method is the HTTP method. http.MethodPost is used when empty.
+	Method string
+	// Headers are added to every request.
+	Headers map[string]string
+	// ContentType is the Content-Type header. When empty, it is "application/json"
+	// for HTTPBodyJSON and templates, and "text/plain; charset=utf-8" for HTTPBodyRaw.
+	ContentType string
+
+	// Format selects the request body. HTTPBodyJSON is used by default.
+	Format HTTPBodyFormat
+	// Encoder encodes records for HTTPBodyJSON. A JSONEncoder is used when nil.
+	Encoder Encoder
+	// Template is a text/template for the request body and overrides Format.
+	// It is executed with HTTPTemplateData and may use the "json" function
+	// to encode values as JSON, for example:
+	//
+	//	{"text": {{json .Line}}, "level": "{{.Record.Level}}"}
+	Template string
+
+	// BatchSize enables batching of up to BatchSize records per request.
+	// When zero or one, every record is sent in its own request.
+	BatchSize int
+	// FlushInterval is how often pending records are sent, in case a request
+	// could not be started right away. DefaultHTTPFlushInterval is used when zero.
+	FlushInterval time.Duration
+
+	// Timeout limits every request. DefaultHTTPTimeout is used when zero.
+	Timeout time.Duration
+	// MaxRetries is the number of retries of a failed request. DefaultHTTPMaxRetries
+	// is used when zero, a negative value disables retries.
+	MaxRetries int
+	// RetryBackoff is the delay before the first retry, doubled for every further retry
+	// up to MaxBackoff, with jitter. A Retry-After header of the response takes precedence,
+	// but is capped at MaxBackoff as well.
+	// DefaultHTTPRetryBackoff and DefaultHTTPMaxBackoff are used when zero.
+	RetryBackoff time.Duration
+	MaxBackoff   time.Duration
+
+	// Client sends the requests. http.DefaultClient is used when nil.
+	Client *http.Client
+	// ErrorHandler receives the errors of background requests.
+	// When nil, they are returned by the next WriteRecord call and reach the
+	// logger's ErrorHandler.
+	ErrorHandler ErrorHandler
+}
+
+// HTTPWriter sends records to an HTTP endpoint, such as a webhook.
+//
+// Records are queued and sent in the background, so a slow or unreachable
+// endpoint does not block the logger. Pending records are sent on Flush and Close.
+// Failed requests are retried on network errors, 408, 429 and 5xx responses with
+// exponential backoff and jitter, honouring the Retry-After header.
+// Requests which still fail are reported as errors, usually *HTTPError.
+type HTTPWriter struct {
+	cfg      HTTPConfig
+	headers  map[string]string
+	template *template.Template
+	retry    retryPolicy
+	batcher  *batcher[httpEntry]
+}
+
+type httpEntry struct {
+	record *Record
+	line   string
+}
+
+// NewHTTPWriter creates a new HTTPWriter.
+func NewHTTPWriter(cfg *HTTPConfig) (*HTTPWriter, error) {
+	if cfg == nil || cfg.URL == "" {
+		return nil, fmt.Errorf("http writer: url is required")
+	}
+
+	c := *cfg
+	if u, err := url.Parse(c.URL); err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("http writer: invalid url %q", c.URL)
+	}
+	if c.Method == "" {
+		c.Method = http.MethodPost
+	}
+	if c.Encoder == nil {
+		c.Encoder = &JSONEncoder{}
+	}
+	if c.FlushInterval <= 0 {
+		c.FlushInterval = DefaultHTTPFlushInterval
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = DefaultHTTPTimeout
+	}
+	if c.MaxRetries == 0 {
+		c.MaxRetries = DefaultHTTPMaxRetries
+	}
+	if c.RetryBackoff <= 0 {
+		c.RetryBackoff = DefaultHTTPRetryBackoff
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = DefaultHTTPMaxBackoff
+	}
+	if c.Client == nil {
+		c.Client = http.DefaultClient
+	}
+
+	w := &HTTPWriter{
+		cfg:   c,
+		retry: retryPolicy{maxRetries: c.MaxRetries, backoff: c.RetryBackoff, maxBackoff: c.MaxBackoff, jitter: true},
+	}
+
+	if c.Template != "" {
+		tmpl, err := template.New("body").Funcs(template.FuncMap{"json": templateJSON}).Parse(c.Template)
+		if err != nil {
+			return nil, fmt.Errorf("http writer: %w", err)
+		}
+		w.template = tmpl
+	}
+
+	contentType := c.ContentType
+	if contentType == "" {
+		contentType = "application/json"
+		if c.Format == HTTPBodyRaw && w.template == nil {
+			contentType = "text/plain; charset=utf-8"
+		}
+	}
+	w.headers = map[string]string{"Content-Type": contentType}
+	for k, v := range c.Headers {
+		w.headers[k] = v
+	}
+
+	w.batcher = newBatcher(max(c.BatchSize, 1), c.FlushInterval, c.ErrorHandler, w.send)
+	return w, nil
+}
+
+// Write sends bytes as an INFO level record.
+func (w *HTTPWriter) Write(bytes []byte) (int, error) {
+	record := &Record{Time: time.Now(), Level: InfoLevel, Message: string(bytes)}
+	if err := w.WriteRecord(record, bytes); err != nil {
+		return 0, err
+	}
+	return len(bytes), nil
+}
+
+// WriteRecord queues the record for sending.
+func (w *HTTPWriter) WriteRecord(record *Record, line []byte) error {
+	// The record is encoded later, after hooks may have modified it.
+	return w.batcher.add(httpEntry{record: cloneRecord(record), line: string(line)})
+}
+
+// Flush sends all pending records.
+func (w *HTTPWriter) Flush() error {
+	return w.batcher.flush()
+}
+
+// Close sends all pending records and stops the writer.
+func (w *HTTPWriter) Close() error {
+	return w.batcher.close()
+}
+
+func (w *HTTPWriter) send(entries []httpEntry) error {
+	body, err := w.body(entries)
+	if err != nil {
+		return fmt.Errorf("http writer: %w", err)
+	}
+
+	req := httpRequest{method: w.cfg.Method, url: w.cfg.URL, headers: w.headers, body: body}
+	err = w.retry.do(context.Background(), func() error {
+		_, err := req.send(context.Background(), w.cfg.Client, w.cfg.Timeout)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("http writer: %s %s: %w", w.cfg.Method, w.cfg.URL, err)
+	}
+	return nil
+}
+
+func (w *HTTPWriter) body(entries []httpEntry) ([]byte, error) {
+	if w.template != nil {
+		data := HTTPTemplateData{
+			Records: make([]*Record, len(entries)),
+			Lines:   make([]string, len(entries)),
+			Record:  entries[0].record,
+			Line:    entries[0].line,
+		}
+		for i, entry := range entries {
+			data.Records[i] = entry.record
+			data.Lines[i] = entry.line
+		}
+
+		var buf bytes.Buffer
+		if err := w.template.Execute(&buf, data); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	if w.cfg.Format == HTTPBodyRaw {
+		lines := make([]string, len(entries))
+		for i, entry := range entries {
+			lines[i] = entry.line
+		}
+		return []byte(strings.Join(lines, "\n")), nil
+	}
+
+	if w.cfg.BatchSize <= 1 {
+		return []byte(w.cfg.Encoder.Encode(entries[0].record)), nil
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for i, entry := range entries {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(w.cfg.Encoder.Encode(entry.record))
+	}
+	buf.WriteByte(']')
+	return buf.Bytes(), nil
+}
+
+// templateJSON encodes a value as JSON inside body templates.
+func templateJSON(value interface{}) (string, error) {
+	if err, ok := value.(error); ok {
+		value = err.Error()
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
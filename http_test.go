@@ -0,0 +1,249 @@
+package balogan
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// decodeText keeps request bodies as text.
+func decodeText(body []byte) (string, error) {
+	return string(body), nil
+}
+
+func TestHTTPWriter_JSON(t *testing.T) {
+	server := newRecordingServer(t, decodeText)
+	writer, err := NewHTTPWriter(&HTTPConfig{
+		URL:     server.URL,
+		Method:  http.MethodPut,
+		Headers: map[string]string{"X-Api-Key": "secret"},
+	})
+	if err != nil {
+		t.Fatalf("NewHTTPWriter failed: %v", err)
+	}
+
+	New(InfoLevel, writer).WithField("order", 42).Warning("slow order")
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	requests := server.captured()
+	if len(requests) != 1 {
+		t.Fatalf("Expected 1 request, got %d", len(requests))
+	}
+	req := requests[0]
+	if req.method != http.MethodPut || req.header.Get("Content-Type") != "application/json" || req.header.Get("X-Api-Key") != "secret" {
+		t.Errorf("Unexpected request %+v", req)
+	}
+
+	var body map[string]interface{}
+	if err := json.Unmarshal([]byte(req.body), &body); err != nil {
+		t.Fatalf("Expected JSON body, got %q: %v", req.body, err)
+	}
+	if body["msg"] != "slow order" || body["level"] != "WARNING" || body["order"] != float64(42) {
+		t.Errorf("Unexpected body %v", body)
+	}
+}
+
+func TestHTTPWriter_Raw(t *testing.T) {
+	server := newRecordingServer(t, decodeText)
+	writer, err := NewHTTPWriter(&HTTPConfig{URL: server.URL, Format: HTTPBodyRaw})
+	if err != nil {
+		t.Fatalf("NewHTTPWriter failed: %v", err)
+	}
+
+	New(InfoLevel, writer).WithField("k", "v").Info("raw line")
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	req := server.captured()[0]
+	if req.body != "INFO k=v raw line" || !strings.HasPrefix(req.header.Get("Content-Type"), "text/plain") {
+		t.Errorf("Expected the formatted line as text, got %q (%s)", req.body, req.header.Get("Content-Type"))
+	}
+}
+
+func TestHTTPWriter_Template(t *testing.T) {
+	server := newRecordingServer(t, decodeText)
+	writer, err := NewHTTPWriter(&HTTPConfig{
+		URL:      server.URL,
+		Template: `{"text": {{json .Record.Message}}, "level": "{{.Record.Level}}", "user": {{json (index .Record.Fields "user")}}}`,
+	})
+	if err != nil {
+		t.Fatalf("NewHTTPWriter failed: %v", err)
+	}
+
+	New(InfoLevel, writer).WithField("user", `jo"hn`).Error("payment failed")
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	expected := `{"text": "payment failed", "level": "ERROR", "user": "jo\"hn"}`
+	if got := server.captured()[0].body; got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+}
+
+func TestHTTPWriter_InvalidTemplate(t *testing.T) {
+	if _, err := NewHTTPWriter(&HTTPConfig{URL: "http://localhost", Template: "{{.Record"}); err == nil {
+		t.Error("Expected error for invalid template")
+	}
+	if _, err := NewHTTPWriter(&HTTPConfig{}); err == nil {
+		t.Error("Expected error without url")
+	}
+}
+
+func TestHTTPWriter_Batch(t *testing.T) {
+	server := newRecordingServer(t, decodeText)
+	writer, err := NewHTTPWriter(&HTTPConfig{URL: server.URL, BatchSize: 10, FlushInterval: time.Hour})
+	if err != nil {
+		t.Fatalf("NewHTTPWriter failed: %v", err)
+	}
+
+	logger := New(InfoLevel, writer)
+	logger.Info("one")
+	logger.Info("two")
+	if len(server.captured()) != 0 {
+		t.Fatal("Records should be queued in batch mode")
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	requests := server.captured()
+	if len(requests) != 1 {
+		t.Fatalf("Expected 1 batch request, got %d", len(requests))
+	}
+	var body []map[string]interface{}
+	if err := json.Unmarshal([]byte(requests[0].body), &body); err != nil || len(body) != 2 {
+		t.Errorf("Expected a JSON array of 2 records, got %q", requests[0].body)
+	}
+}
+
+func TestHTTPWriter_BatchKeepsRecordSnapshot(t *testing.T) {
+	server := newRecordingServer(t, decodeText)
+	writer, err := NewHTTPWriter(&HTTPConfig{URL: server.URL, BatchSize: 2, FlushInterval: time.Millisecond})
+	if err != nil {
+		t.Fatalf("NewHTTPWriter failed: %v", err)
+	}
+
+	// The hook modifies the shared record while the batch is being encoded.
+	logger := New(InfoLevel, writer).WithHook(AfterWrite, NewHook(func(record *Record) error {
+		record.Fields["stage"] = "after"
+		record.Prefixes = append(record.Prefixes, "modified")
+		return nil
+	})).WithField("stage", "write")
+
+	for range 50 {
+		logger.Info("snapshot")
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	for _, req := range server.captured() {
+		if strings.Contains(req.body, `"after"`) {
+			t.Fatalf("Records should be encoded as written, got %q", req.body)
+		}
+	}
+}
+
+func TestHTTPWriter_RetryAfter(t *testing.T) {
+	server := newRecordingServer(t, decodeText)
+	server.failures = 1
+	server.status = http.StatusTooManyRequests
+	server.retryAfter = "1"
+
+	writer, err := NewHTTPWriter(&HTTPConfig{URL: server.URL, RetryBackoff: time.Millisecond})
+	if err != nil {
+		t.Fatalf("NewHTTPWriter failed: %v", err)
+	}
+
+	start := time.Now()
+	_, _ = writer.Write([]byte("throttled"))
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Request should succeed after retry, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("Expected Retry-After to delay the retry, retried after %v", elapsed)
+	}
+	if len(server.captured()) != 2 {
+		t.Errorf("Expected 2 requests, got %d", len(server.captured()))
+	}
+}
+
+func TestHTTPWriter_RetryAfterCapped(t *testing.T) {
+	server := newRecordingServer(t, decodeText)
+	server.failures = 1
+	server.status = http.StatusTooManyRequests
+	server.retryAfter = "3600"
+
+	writer, err := NewHTTPWriter(&HTTPConfig{URL: server.URL, MaxBackoff: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("NewHTTPWriter failed: %v", err)
+	}
+
+	start := time.Now()
+	_, _ = writer.Write([]byte("throttled"))
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Request should succeed after retry, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected Retry-After to be capped at MaxBackoff, retried after %v", elapsed)
+	}
+}
+
+func TestHTTPWriter_FailuresToErrorHandler(t *testing.T) {
+	server := newRecordingServer(t, decodeText)
+	server.failures = 10
+	server.status = http.StatusInternalServerError
+
+	handled := make(chan error, 1)
+	writer, err := NewHTTPWriter(&HTTPConfig{
+		URL:          server.URL,
+		MaxRetries:   2,
+		RetryBackoff: time.Millisecond,
+		ErrorHandler: ErrorHandlerFunc(func(err error) { handled <- err }),
+	})
+	if err != nil {
+		t.Fatalf("NewHTTPWriter failed: %v", err)
+	}
+	defer writer.Close()
+
+	New(InfoLevel, writer).Error("lost?")
+
+	select {
+	case err := <-handled:
+		var httpErr *HTTPError
+		if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusInternalServerError {
+			t.Errorf("Expected HTTPError, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the failure in the error handler")
+	}
+	if len(server.captured()) != 3 {
+		t.Errorf("Expected 1 attempt and 2 retries, got %d requests", len(server.captured()))
+	}
+}
+
+func TestHTTPWriter_DoesNotBlockLogger(t *testing.T) {
+	server := newRecordingServer(t, decodeText)
+	server.failures = 1
+	server.status = http.StatusServiceUnavailable
+	server.retryAfter = "1"
+
+	writer, err := NewHTTPWriter(&HTTPConfig{URL: server.URL})
+	if err != nil {
+		t.Fatalf("NewHTTPWriter failed: %v", err)
+	}
+	defer writer.Close()
+
+	start := time.Now()
+	New(InfoLevel, writer).Info("endpoint is down")
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("A failing endpoint should not block the logger, Info took %v", elapsed)
+	}
+}
@@ -0,0 +1,94 @@
+package balogan
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"sync"
+)
+
+// maxWriterLineSize bounds the partial line buffered by Logger.Writer.
+// Longer lines are logged in chunks of this size.
+const maxWriterLineSize = 64 << 10
+
+// logLineWriter is the io.WriteCloser returned by Logger.Writer.
+type logLineWriter struct {
+	mu     sync.Mutex
+	logger *Logger
+	level  LogLevel
+	buf    []byte
+	closed bool
+}
+
+// Writer returns an io.WriteCloser which logs every line written to it as a
+// record at the given level, with the logger's fields, prefixes and name.
+//
+// Partial writes are buffered until a newline arrives, a trailing '\r' is
+// dropped and empty lines are skipped. Close logs an unterminated last line;
+// it does not close the logger. Writing at FatalLevel or PanicLevel logs the
+// records without exiting or panicking.
+//
+// Parameters:
+//
+//	level: The level of the logged records.
+//
+// Example:
+//
+//	cmd := exec.Command("make", "build")
+//	cmd.Stdout = logger.WithField("cmd", "make").Writer(InfoLevel)
+//	cmd.Stderr = logger.WithField("cmd", "make").Writer(ErrorLevel)
+//
+//	server := &http.Server{ErrorLog: log.New(logger.Writer(ErrorLevel), "", 0)}
+func (l *Logger) Writer(level LogLevel) io.WriteCloser {
+	return &logLineWriter{logger: l, level: level}
+}
+
+func (w *logLineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return 0, os.ErrClosed
+	}
+
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.emit(w.buf[:i])
+		w.buf = w.buf[i+1:]
+	}
+	for len(w.buf) >= maxWriterLineSize {
+		w.emit(w.buf[:maxWriterLineSize])
+		w.buf = w.buf[maxWriterLineSize:]
+	}
+
+	// Move the partial line to the start, so the buffer does not grow forever.
+	w.buf = append(w.buf[:0:0], w.buf...)
+	return len(p), nil
+}
+
+// Close logs the buffered partial line, if any.
+func (w *logLineWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return os.ErrClosed
+	}
+	w.closed = true
+	w.emit(w.buf)
+	w.buf = nil
+	return nil
+}
+
+// emit logs one line. The caller must hold w.mu.
+func (w *logLineWriter) emit(line []byte) {
+	line = bytes.TrimSuffix(line, []byte("\r"))
+	if len(line) == 0 || !w.logger.shouldLog(w.level) {
+		return
+	}
+	w.logger.log(w.level, string(line))
+}
@@ -0,0 +1,92 @@
+package balogan
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLogger_Writer(t *testing.T) {
+	writer := &lineWriter{}
+	logger := New(DebugLevel, writer).WithField("cmd", "make")
+
+	w := logger.Writer(WarningLevel)
+	_, _ = w.Write([]byte("first li"))
+	_, _ = w.Write([]byte("ne\r\nsecond line\n\n  indented\nunterminated"))
+
+	expected := []string{"WARNING cmd=make first line", "WARNING cmd=make second line", "WARNING cmd=make   indented"}
+	if strings.Join(writer.lines, "|") != strings.Join(expected, "|") {
+		t.Errorf("Expected %q, got %q", expected, writer.lines)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if last := writer.lines[len(writer.lines)-1]; last != "WARNING cmd=make unterminated" {
+		t.Errorf("Expected Close to log the partial line, got %q", last)
+	}
+
+	if _, err := w.Write([]byte("late\n")); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Expected os.ErrClosed, got %v", err)
+	}
+	if err := w.Close(); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Expected os.ErrClosed on second close, got %v", err)
+	}
+}
+
+func TestLogger_Writer_Level(t *testing.T) {
+	writer := &lineWriter{}
+	w := New(InfoLevel, writer).Writer(DebugLevel)
+	fmt.Fprintln(w, "filtered")
+
+	if len(writer.lines) != 0 {
+		t.Errorf("Expected lines below the logger level to be dropped, got %q", writer.lines)
+	}
+}
+
+func TestLogger_Writer_LongLine(t *testing.T) {
+	writer := &lineWriter{}
+	w := New(InfoLevel, writer).Writer(InfoLevel)
+	_, _ = w.Write([]byte(strings.Repeat("x", maxWriterLineSize+10)))
+
+	if len(writer.records) != 1 || len(writer.records[0].Message) != maxWriterLineSize {
+		t.Fatalf("Expected a full chunk to be logged, got %d records", len(writer.records))
+	}
+	_ = w.Close()
+	if len(writer.records) != 2 || writer.records[1].Message != strings.Repeat("x", 10) {
+		t.Errorf("Expected the rest of the line on Close, got %d records", len(writer.records))
+	}
+}
+
+func TestLogger_Writer_StdLogger(t *testing.T) {
+	mockWriter := &MockWriter{}
+	logger := New(InfoLevel, mockWriter).WithEncoder(&JSONEncoder{}).WithCaller(true)
+
+	stdLogger := log.New(logger.Writer(ErrorLevel), "http: ", 0)
+	stdLogger.Print("TLS handshake error")
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(mockWriter.Bytes(), &decoded); err != nil {
+		t.Fatalf("Output is not valid JSON: %v", err)
+	}
+	if decoded["level"] != "ERROR" || decoded["msg"] != "http: TLS handshake error" {
+		t.Errorf("Unexpected record %v", decoded)
+	}
+	if caller, _ := decoded["caller"].(string); strings.Contains(caller, "iowriter.go") {
+		t.Errorf("Expected the writer's frames to be skipped, got %q", caller)
+	}
+}
+
+func TestLogger_Writer_Caller(t *testing.T) {
+	writer := &lineWriter{}
+	w := New(InfoLevel, writer).WithCaller(true).Writer(InfoLevel)
+	_, _ = w.Write([]byte("direct\n"))
+
+	if caller := writer.records[0].Caller; !strings.Contains(caller, "/iowriter_test.go:") {
+		t.Errorf("Expected caller in this test file, got %q", caller)
+	}
+}
@@ -0,0 +1,197 @@
+package balogan
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// DefaultJournaldSocket is the socket of the systemd-journald native protocol.
+const DefaultJournaldSocket = "/run/systemd/journal/socket"
+
+// journaldReservedFields are set by the writer itself.
+// Record fields with the same name are written with a "FIELD_" prefix.
+var journaldReservedFields = map[string]bool{
+	"MESSAGE":           true,
+	"PRIORITY":          true,
+	"SYSLOG_IDENTIFIER": true,
+	"CODE_FILE":         true,
+	"CODE_LINE":         true,
+	"LOGGER":            true,
+}
+
+// JournaldConfig configures a JournaldWriter.
+type JournaldConfig struct {
+	// Socket is the journald socket path. DefaultJournaldSocket is used when empty.
+	Socket string
+	// Identifier is sent as SYSLOG_IDENTIFIER. The executable name is used when empty.
+	Identifier string
+	// MaxDatagramSize sends entries larger than this size through a file descriptor
+	// right away. When zero, the datagram is tried first and the file descriptor
+	// is only used when the kernel rejects it as too large.
+	MaxDatagramSize int
+}
+
+// JournaldWriter sends records to systemd-journald using its native datagram protocol.
+//
+// The record's level is sent as PRIORITY (see LogLevel.SyslogSeverity),
+// the message as MESSAGE and every field as an uppercase journal field
+// (for example "user_id" becomes USER_ID). Characters which are not allowed in
+// journal field names are replaced with '_'.
+//
+// Entries which are too large for a single datagram are written to an unlinked
+// temporary file and its file descriptor is passed to journald, the same way
+// sd_journal_send does.
+type JournaldWriter struct {
+	mu   sync.Mutex
+	cfg  JournaldConfig
+	conn *net.UnixConn
+}
+
+// NewJournaldWriter creates a new JournaldWriter connected to the journald socket.
+func NewJournaldWriter(cfg *JournaldConfig) (*JournaldWriter, error) {
+	c := JournaldConfig{}
+	if cfg != nil {
+		c = *cfg
+	}
+	if c.Socket == "" {
+		c.Socket = DefaultJournaldSocket
+	}
+	if c.Identifier == "" {
+		c.Identifier = filepath.Base(os.Args[0])
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: c.Socket, Net: "unixgram"})
+	if err != nil {
+		return nil, err
+	}
+
+	return &JournaldWriter{cfg: c, conn: conn}, nil
+}
+
+// Write sends bytes as the MESSAGE of an INFO level journal entry.
+func (w *JournaldWriter) Write(bytes []byte) (int, error) {
+	record := &Record{Time: time.Now(), Level: InfoLevel, Message: string(bytes)}
+	if err := w.WriteRecord(record, bytes); err != nil {
+		return 0, err
+	}
+	return len(bytes), nil
+}
+
+// WriteRecord sends the record as a journal entry.
+func (w *JournaldWriter) WriteRecord(record *Record, line []byte) error {
+	entry := w.entry(record)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn == nil {
+		return os.ErrClosed
+	}
+
+	if w.cfg.MaxDatagramSize <= 0 || len(entry) <= w.cfg.MaxDatagramSize {
+		_, err := w.conn.Write(entry)
+		if err == nil || !(errors.Is(err, syscall.EMSGSIZE) || errors.Is(err, syscall.ENOBUFS)) {
+			return err
+		}
+	}
+
+	return sendJournaldFD(w.conn, entry)
+}
+
+func (w *JournaldWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn == nil {
+		return os.ErrClosed
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+// entry serializes the record in the journald native format.
+func (w *JournaldWriter) entry(record *Record) []byte {
+	var buf bytes.Buffer
+
+	writeJournaldField(&buf, "PRIORITY", strconv.Itoa(record.Level.SyslogSeverity()))
+	writeJournaldField(&buf, "MESSAGE", record.Message)
+	writeJournaldField(&buf, "SYSLOG_IDENTIFIER", w.cfg.Identifier)
+
+	if record.Logger != "" {
+		writeJournaldField(&buf, "LOGGER", record.Logger)
+	}
+	if record.Caller != "" {
+		file, line, _ := strings.Cut(record.Caller, ":")
+		writeJournaldField(&buf, "CODE_FILE", file)
+		writeJournaldField(&buf, "CODE_LINE", line)
+	}
+
+	for _, k := range sortedKeys(record.Fields) {
+		name := journaldFieldName(k)
+		if name == "" {
+			continue
+		}
+		if journaldReservedFields[name] {
+			name = "FIELD_" + name
+		}
+		writeJournaldField(&buf, name, logfmtString(record.Fields[k]))
+	}
+
+	return buf.Bytes()
+}
+
+// writeJournaldField writes NAME=value, or the binary-safe form
+// NAME\n<64-bit little-endian length><value>\n for values containing newlines.
+func writeJournaldField(buf *bytes.Buffer, name, value string) {
+	buf.WriteString(name)
+	if !strings.Contains(value, "\n") {
+		buf.WriteByte('=')
+		buf.WriteString(value)
+		buf.WriteByte('\n')
+		return
+	}
+
+	buf.WriteByte('\n')
+	_ = binary.Write(buf, binary.LittleEndian, uint64(len(value)))
+	buf.WriteString(value)
+	buf.WriteByte('\n')
+}
+
+// journaldFieldName converts a field key into a valid journal field name:
+// uppercase letters, digits and underscores, not starting with an underscore
+// or a digit, at most 64 characters.
+func journaldFieldName(key string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, key)
+
+	// Names starting with '_' are trusted fields set by journald itself.
+	name = strings.TrimLeft(name, "_")
+	if name == "" {
+		return ""
+	}
+	if name[0] >= '0' && name[0] <= '9' {
+		name = "F_" + name
+	}
+	if len(name) > 64 {
+		name = name[:64]
+	}
+	return name
+}
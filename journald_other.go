@@ -0,0 +1,13 @@
+//go:build !unix
+
+package balogan
+
+import (
+	"errors"
+	"net"
+)
+
+// sendJournaldFD is not supported on platforms without unix file descriptor passing.
+func sendJournaldFD(conn *net.UnixConn, entry []byte) error {
+	return errors.New("journald: entry too large for a datagram")
+}
@@ -0,0 +1,173 @@
+//go:build unix
+
+package balogan
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func listenJournald(t *testing.T) (*net.UnixConn, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "journal.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Skipf("unixgram sockets unavailable: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn, path
+}
+
+// parseJournaldEntry decodes the journald native format into a map.
+func parseJournaldEntry(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	fields := map[string]string{}
+	for len(data) > 0 {
+		i := bytes.IndexAny(data, "=\n")
+		if i < 0 {
+			t.Fatalf("Malformed journal entry: %q", data)
+		}
+		name := string(data[:i])
+		if data[i] == '=' {
+			end := bytes.IndexByte(data[i:], '\n')
+			fields[name] = string(data[i+1 : i+end])
+			data = data[i+end+1:]
+			continue
+		}
+		size := binary.LittleEndian.Uint64(data[i+1 : i+9])
+		start := i + 9
+		fields[name] = string(data[start : start+int(size)])
+		if data[start+int(size)] != '\n' {
+			t.Fatalf("Binary field %s not terminated by newline", name)
+		}
+		data = data[start+int(size)+1:]
+	}
+	return fields
+}
+
+func TestJournaldWriter_Datagram(t *testing.T) {
+	listener, path := listenJournald(t)
+
+	w, err := NewJournaldWriter(&JournaldConfig{Socket: path, Identifier: "shop"})
+	if err != nil {
+		t.Fatalf("NewJournaldWriter() error = %v", err)
+	}
+	defer w.Close()
+
+	var _ RecordWriter = w
+
+	logger := New(InfoLevel, w).WithName("orders").WithFields(Fields{
+		"user_id":  42,
+		"http.url": "/orders",
+		"stack":    "line1\nline2",
+		"message":  "clash",
+		"_secret":  "trusted",
+	})
+	logger.Warning("Order delayed")
+
+	buf := make([]byte, 65536)
+	_ = listener.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, err := listener.Read(buf)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+
+	fields := parseJournaldEntry(t, buf[:n])
+	expected := map[string]string{
+		"PRIORITY":          "4",
+		"MESSAGE":           "Order delayed",
+		"SYSLOG_IDENTIFIER": "shop",
+		"LOGGER":            "orders",
+		"USER_ID":           "42",
+		"HTTP_URL":          "/orders",
+		"STACK":             "line1\nline2",
+		"FIELD_MESSAGE":     "clash",
+		"SECRET":            "trusted",
+	}
+	for k, v := range expected {
+		if fields[k] != v {
+			t.Errorf("Journal field %s = %q, want %q", k, fields[k], v)
+		}
+	}
+}
+
+func TestJournaldWriter_LargeEntryFD(t *testing.T) {
+	listener, path := listenJournald(t)
+
+	w, err := NewJournaldWriter(&JournaldConfig{Socket: path, MaxDatagramSize: 128})
+	if err != nil {
+		t.Fatalf("NewJournaldWriter() error = %v", err)
+	}
+	defer w.Close()
+
+	message := strings.Repeat("large ", 100)
+	if _, err := w.Write([]byte(message)); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	buf := make([]byte, 1024)
+	oob := make([]byte, syscall.CmsgSpace(4))
+	_ = listener.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, oobn, _, _, err := listener.ReadMsgUnix(buf, oob)
+	if err != nil {
+		t.Fatalf("ReadMsgUnix() error = %v", err)
+	}
+	if n != 0 {
+		t.Errorf("Expected empty datagram with file descriptor, got %d bytes", n)
+	}
+
+	msgs, err := syscall.ParseSocketControlMessage(oob[:oobn])
+	if err != nil || len(msgs) != 1 {
+		t.Fatalf("Expected one control message, got %d (%v)", len(msgs), err)
+	}
+	fds, err := syscall.ParseUnixRights(&msgs[0])
+	if err != nil || len(fds) != 1 {
+		t.Fatalf("Expected one file descriptor, got %v (%v)", fds, err)
+	}
+
+	file := os.NewFile(uintptr(fds[0]), "journal-entry")
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if st, ok := info.Sys().(*syscall.Stat_t); ok && st.Nlink != 0 {
+		t.Errorf("Passed file should be unlinked, has %d links", st.Nlink)
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		t.Fatalf("Seek() error = %v", err)
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if fields := parseJournaldEntry(t, data); fields["MESSAGE"] != message {
+		t.Errorf("Unexpected MESSAGE from file descriptor: %q", fields["MESSAGE"])
+	}
+}
+
+func TestJournaldFieldName(t *testing.T) {
+	tests := map[string]string{
+		"user_id":  "USER_ID",
+		"http.url": "HTTP_URL",
+		"_trusted": "TRUSTED",
+		"2fa":      "F_2FA",
+		"ünï":      "N_",
+		"___":      "",
+	}
+	for input, expected := range tests {
+		if got := journaldFieldName(input); got != expected {
+			t.Errorf("journaldFieldName(%q) = %q, want %q", input, got, expected)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+//go:build unix
+
+package balogan
+
+import (
+	"net"
+	"os"
+	"syscall"
+)
+
+// sendJournaldFD writes the entry to an unlinked temporary file and passes
+// its file descriptor to journald. /dev/shm is preferred so the data stays in memory.
+func sendJournaldFD(conn *net.UnixConn, entry []byte) error {
+	dir := "/dev/shm"
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		dir = os.TempDir()
+	}
+
+	file, err := os.CreateTemp(dir, "balogan-journal-*")
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	// journald only accepts files which are no longer linked in the file system.
+	if err := os.Remove(file.Name()); err != nil {
+		return err
+	}
+	if _, err := file.Write(entry); err != nil {
+		return err
+	}
+
+	// WriteMsgUnix refuses connected datagram sockets, so send through the raw connection.
+	rawConn, err := conn.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	rights := syscall.UnixRights(int(file.Fd()))
+	var sendErr error
+	err = rawConn.Write(func(fd uintptr) bool {
+		sendErr = syscall.Sendmsg(int(fd), nil, rights, nil, 0)
+		return sendErr != syscall.EAGAIN
+	})
+	if err != nil {
+		return err
+	}
+	return sendErr
+}
@@ -35,6 +35,60 @@ func (level LogLevel) String() string {
 	}
 }
 
+// SyslogSeverity returns the syslog severity (RFC 5424) matching the log level.
+// It is used by writers for protocols built on syslog severities, such as GELF, syslog and journald.
+//
+//	TRACE, DEBUG -> 7 (debug)
+//	INFO         -> 6 (informational)
+//	WARNING      -> 4 (warning)
+//	ERROR        -> 3 (error)
+//	FATAL        -> 2 (critical)
+//	PANIC        -> 1 (alert)
+func (level LogLevel) SyslogSeverity() int {
+	switch {
+	case level <= DebugLevel:
+		return 7
+	case level == InfoLevel:
+		return 6
+	case level == WarningLevel:
+		return 4
+	case level == ErrorLevel:
+		return 3
+	case level == FatalLevel:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// OTelSeverity returns the OpenTelemetry severity number matching the log level.
+//
+//	TRACE   -> 1 (TRACE)
+//	DEBUG   -> 5 (DEBUG)
+//	INFO    -> 9 (INFO)
+//	WARNING -> 13 (WARN)
+//	ERROR   -> 17 (ERROR)
+//	FATAL   -> 21 (FATAL)
+//	PANIC   -> 22 (FATAL2)
+func (level LogLevel) OTelSeverity() int {
+	switch {
+	case level <= TraceLevel:
+		return 1
+	case level == DebugLevel:
+		return 5
+	case level == InfoLevel:
+		return 9
+	case level == WarningLevel:
+		return 13
+	case level == ErrorLevel:
+		return 17
+	case level == FatalLevel:
+		return 21
+	default:
+		return 22
+	}
+}
+
 // IsEnabled checks if the given level should be logged based on the minimum level.
 func (level LogLevel) IsEnabled(minLevel LogLevel) bool {
 	return level >= minLevel
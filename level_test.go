@@ -220,3 +220,37 @@ func TestLogLevel_Comprehensive(t *testing.T) {
 		}
 	}
 }
+
+func TestLogLevel_SyslogSeverity(t *testing.T) {
+	tests := map[LogLevel]int{
+		TraceLevel:   7,
+		DebugLevel:   7,
+		InfoLevel:    6,
+		WarningLevel: 4,
+		ErrorLevel:   3,
+		FatalLevel:   2,
+		PanicLevel:   1,
+	}
+	for level, expected := range tests {
+		if got := level.SyslogSeverity(); got != expected {
+			t.Errorf("%s.SyslogSeverity() = %d, want %d", level, got, expected)
+		}
+	}
+}
+
+func TestLogLevel_OTelSeverity(t *testing.T) {
+	tests := map[LogLevel]int{
+		TraceLevel:   1,
+		DebugLevel:   5,
+		InfoLevel:    9,
+		WarningLevel: 13,
+		ErrorLevel:   17,
+		FatalLevel:   21,
+		PanicLevel:   22,
+	}
+	for level, expected := range tests {
+		if got := level.OTelSeverity(); got != expected {
+			t.Errorf("%s.OTelSeverity() = %d, want %d", level, got, expected)
+		}
+	}
+}
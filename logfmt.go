@@ -0,0 +1,293 @@
+package balogan
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// LogfmtEncoder encodes the whole record as a single logfmt line.
+//
+// Keys are written in a stable order: time, level, message, logger, caller,
+// followed by the fields sorted by key. Logger and caller are omitted when empty.
+// Fields whose keys clash with one of the record keys are written as "fields.<key>".
+//
+// Values are quoted when they are empty or contain spaces, '=', '"' or
+// control characters. Inside quotes, backslashes, quotes, newlines, tabs and
+// other control characters are escaped, so every record stays on one line.
+// Characters which are not allowed in keys are replaced with '_'.
+//
+// Example output:
+//
+//	time=2024-12-13T15:30:45Z level=info msg="User logged in" user=john
+type LogfmtEncoder struct {
+	// Key names. The Default*Key constants are used for empty values.
+	TimeKey    string
+	LevelKey   string
+	MessageKey string
+	LoggerKey  string
+	CallerKey  string
+
+	// TimeFormat is the time.Format layout of the time value.
+	// time.RFC3339Nano is used when empty.
+	TimeFormat string
+
+	// UppercaseLevel writes levels as "INFO" instead of "info".
+	UppercaseLevel bool
+}
+
+func (e *LogfmtEncoder) Encode(record *Record) string {
+	timeKey := keyOrDefault(e.TimeKey, DefaultTimeKey)
+	levelKey := keyOrDefault(e.LevelKey, DefaultLevelKey)
+	messageKey := keyOrDefault(e.MessageKey, DefaultMessageKey)
+	loggerKey := keyOrDefault(e.LoggerKey, DefaultLoggerKey)
+	callerKey := keyOrDefault(e.CallerKey, DefaultCallerKey)
+
+	timeFormat := e.TimeFormat
+	if timeFormat == "" {
+		timeFormat = time.RFC3339Nano
+	}
+
+	level := strings.ToLower(record.Level.String())
+	if e.UppercaseLevel {
+		level = record.Level.String()
+	}
+
+	var b strings.Builder
+	writeLogfmtPair(&b, timeKey, record.Time.Format(timeFormat))
+	writeLogfmtPair(&b, levelKey, level)
+	writeLogfmtPair(&b, messageKey, record.Message)
+	if record.Logger != "" {
+		writeLogfmtPair(&b, loggerKey, record.Logger)
+	}
+	if record.Caller != "" {
+		writeLogfmtPair(&b, callerKey, record.Caller)
+	}
+
+	reserved := map[string]bool{
+		timeKey: true, levelKey: true, messageKey: true, loggerKey: true, callerKey: true,
+	}
+	for _, k := range sortedKeys(record.Fields) {
+		key := logfmtKey(k)
+		if reserved[key] {
+			key = "fields." + key
+		}
+		writeLogfmtPair(&b, key, logfmtString(record.Fields[k]))
+	}
+
+	return b.String()
+}
+
+// LogfmtPair is a single key/value pair of a logfmt line.
+type LogfmtPair struct {
+	Key   string
+	Value string
+}
+
+// Errors returned by ParseLogfmt.
+var (
+	ErrLogfmtUnterminatedQuote = errors.New("logfmt: unterminated quoted value")
+	ErrLogfmtInvalidEscape     = errors.New("logfmt: invalid escape sequence")
+	ErrLogfmtUnexpectedQuote   = errors.New("logfmt: unexpected quote")
+)
+
+// ParseLogfmt parses a logfmt line into key/value pairs in their original order.
+// It accepts everything LogfmtEncoder and LogfmtFormatter produce.
+// A key without '=' is returned with an empty value.
+//
+// Parameters:
+//
+//	line: The logfmt line to parse.
+//
+// Example:
+//
+//	pairs, err := ParseLogfmt(`level=info msg="User logged in" user=john`)
+//	// pairs: [{level info} {msg User logged in} {user john}]
+func ParseLogfmt(line string) ([]LogfmtPair, error) {
+	var pairs []LogfmtPair
+
+	i := 0
+	for {
+		for i < len(line) && line[i] <= ' ' {
+			i++
+		}
+		if i >= len(line) {
+			return pairs, nil
+		}
+
+		start := i
+		for i < len(line) && line[i] > ' ' && line[i] != '=' {
+			if line[i] == '"' {
+				return nil, fmt.Errorf("%w at offset %d", ErrLogfmtUnexpectedQuote, i)
+			}
+			i++
+		}
+		pair := LogfmtPair{Key: line[start:i]}
+
+		if i < len(line) && line[i] == '=' {
+			i++
+			if i < len(line) && line[i] == '"' {
+				value, next, err := parseLogfmtQuoted(line, i)
+				if err != nil {
+					return nil, err
+				}
+				pair.Value = value
+				i = next
+			} else {
+				start = i
+				for i < len(line) && line[i] > ' ' {
+					if line[i] == '"' {
+						return nil, fmt.Errorf("%w at offset %d", ErrLogfmtUnexpectedQuote, i)
+					}
+					i++
+				}
+				pair.Value = line[start:i]
+			}
+		}
+
+		pairs = append(pairs, pair)
+	}
+}
+
+// parseLogfmtQuoted parses a quoted value starting at the opening quote
+// and returns the unescaped value and the offset after the closing quote.
+func parseLogfmtQuoted(line string, i int) (string, int, error) {
+	var b strings.Builder
+
+	start := i
+	i++
+	for i < len(line) {
+		c := line[i]
+		switch c {
+		case '"':
+			return b.String(), i + 1, nil
+		case '\\':
+			if i+1 >= len(line) {
+				return "", 0, fmt.Errorf("%w at offset %d", ErrLogfmtUnterminatedQuote, start)
+			}
+			i++
+			switch line[i] {
+			case '"', '\\', '/':
+				b.WriteByte(line[i])
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case 'u':
+				if i+4 >= len(line) {
+					return "", 0, fmt.Errorf("%w at offset %d", ErrLogfmtInvalidEscape, i-1)
+				}
+				r, err := strconv.ParseUint(line[i+1:i+5], 16, 16)
+				if err != nil {
+					return "", 0, fmt.Errorf("%w at offset %d", ErrLogfmtInvalidEscape, i-1)
+				}
+				b.WriteRune(rune(r))
+				i += 4
+			default:
+				return "", 0, fmt.Errorf("%w at offset %d", ErrLogfmtInvalidEscape, i-1)
+			}
+			i++
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+
+	return "", 0, fmt.Errorf("%w at offset %d", ErrLogfmtUnterminatedQuote, start)
+}
+
+func writeLogfmtPair(b *strings.Builder, key, value string) {
+	if b.Len() > 0 {
+		b.WriteByte(' ')
+	}
+	b.WriteString(key)
+	b.WriteByte('=')
+	b.WriteString(logfmtValue(value))
+}
+
+// logfmtString converts a field value to the string written to logfmt output.
+func logfmtString(value interface{}) string {
+	if err, ok := value.(error); ok {
+		return err.Error()
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// logfmtKey replaces characters which are not allowed in logfmt keys with '_'.
+func logfmtKey(key string) string {
+	if key == "" {
+		return "_"
+	}
+
+	valid := true
+	for i := 0; i < len(key); i++ {
+		if c := key[i]; c <= ' ' || c == '=' || c == '"' || c == 0x7f {
+			valid = false
+			break
+		}
+	}
+	if valid && utf8.ValidString(key) {
+		return key
+	}
+
+	var b strings.Builder
+	for _, r := range key {
+		if r <= ' ' || r == '=' || r == '"' || r == 0x7f || r == utf8.RuneError {
+			b.WriteByte('_')
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// logfmtValue quotes and escapes a value when required.
+func logfmtValue(value string) string {
+	if value != "" && !logfmtNeedsQuoting(value) {
+		return value
+	}
+
+	const hex = "0123456789abcdef"
+
+	var b strings.Builder
+	b.Grow(len(value) + 2)
+	b.WriteByte('"')
+	for _, r := range value {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if r < ' ' || r == 0x7f {
+				b.WriteString(`\u00`)
+				b.WriteByte(hex[r>>4])
+				b.WriteByte(hex[r&0xf])
+				continue
+			}
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+func logfmtNeedsQuoting(value string) bool {
+	for i := 0; i < len(value); i++ {
+		if c := value[i]; c <= ' ' || c == '=' || c == '"' || c == '\\' || c == 0x7f {
+			return true
+		}
+	}
+	return !utf8.ValidString(value)
+}
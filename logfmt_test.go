@@ -0,0 +1,198 @@
+package balogan
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogfmtEncoder_Encode(t *testing.T) {
+	record := &Record{
+		Time:    time.Date(2024, 12, 13, 15, 30, 45, 0, time.UTC),
+		Level:   InfoLevel,
+		Message: "User logged in",
+		Fields:  Fields{"user": "john", "attempt": 2, "level": "clash"},
+		Logger:  "api",
+	}
+
+	got := (&LogfmtEncoder{}).Encode(record)
+	expected := `time=2024-12-13T15:30:45Z level=info msg="User logged in" logger=api attempt=2 fields.level=clash user=john`
+	if got != expected {
+		t.Errorf("Encode() =\n%s\nwant\n%s", got, expected)
+	}
+
+	got = (&LogfmtEncoder{UppercaseLevel: true, MessageKey: "message", TimeKey: "ts"}).Encode(&Record{
+		Time:    record.Time,
+		Level:   ErrorLevel,
+		Message: "failed",
+	})
+	expected = `ts=2024-12-13T15:30:45Z level=ERROR message=failed`
+	if got != expected {
+		t.Errorf("Encode() =\n%s\nwant\n%s", got, expected)
+	}
+}
+
+func TestLogfmtValue_Escaping(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"simple", "simple"},
+		{"", `""`},
+		{"with space", `"with space"`},
+		{"a=b", `"a=b"`},
+		{`say "hi"`, `"say \"hi\""`},
+		{"line1\nline2", `"line1\nline2"`},
+		{"tab\there", `"tab\there"`},
+		{`back\slash`, `"back\\slash"`},
+		{"bell\x07", `"bell\u0007"`},
+		{"unicode ✓", `"unicode ✓"`},
+		{"ünïcode", "ünïcode"},
+	}
+
+	for _, tt := range tests {
+		if got := logfmtValue(tt.input); got != tt.expected {
+			t.Errorf("logfmtValue(%q) = %s, want %s", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestLogfmtKey_Sanitize(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"user_id", "user_id"},
+		{"http.status", "http.status"},
+		{"with space", "with_space"},
+		{"a=b", "a_b"},
+		{`q"uote`, "q_uote"},
+		{"new\nline", "new_line"},
+		{"", "_"},
+	}
+
+	for _, tt := range tests {
+		if got := logfmtKey(tt.input); got != tt.expected {
+			t.Errorf("logfmtKey(%q) = %q, want %q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestParseLogfmt(t *testing.T) {
+	pairs, err := ParseLogfmt(`level=info msg="User \"john\" logged in\n" empty="" bare  path=/a/b flag`)
+	if err != nil {
+		t.Fatalf("ParseLogfmt() error = %v", err)
+	}
+
+	expected := []LogfmtPair{
+		{"level", "info"},
+		{"msg", "User \"john\" logged in\n"},
+		{"empty", ""},
+		{"bare", ""},
+		{"path", "/a/b"},
+		{"flag", ""},
+	}
+	if !reflect.DeepEqual(pairs, expected) {
+		t.Errorf("ParseLogfmt() =\n%v\nwant\n%v", pairs, expected)
+	}
+}
+
+func TestParseLogfmt_Errors(t *testing.T) {
+	tests := []struct {
+		input string
+		err   error
+	}{
+		{`msg="unterminated`, ErrLogfmtUnterminatedQuote},
+		{`msg="trailing\`, ErrLogfmtUnterminatedQuote},
+		{`msg="bad \x escape"`, ErrLogfmtInvalidEscape},
+		{`msg="bad \u12"`, ErrLogfmtInvalidEscape},
+		{`ke"y=value`, ErrLogfmtUnexpectedQuote},
+		{`key=val"ue`, ErrLogfmtUnexpectedQuote},
+	}
+
+	for _, tt := range tests {
+		if _, err := ParseLogfmt(tt.input); !errors.Is(err, tt.err) {
+			t.Errorf("ParseLogfmt(%q) error = %v, want %v", tt.input, err, tt.err)
+		}
+	}
+}
+
+func TestLogfmt_RoundTrip(t *testing.T) {
+	values := []string{
+		"simple",
+		"",
+		"with space",
+		"a=b=c",
+		`quotes "inside"`,
+		"multi\nline\r\nvalue",
+		"tab\tseparated",
+		`C:\path\to\file`,
+		"control\x00\x01\x1f\x7f chars",
+		"unicode ✓ ünïcode",
+		`{"json":"value"}`,
+	}
+
+	fields := Fields{}
+	for i, v := range values {
+		fields[string(rune('a'+i))] = v
+	}
+
+	record := &Record{
+		Time:    time.Date(2024, 12, 13, 15, 30, 45, 123000000, time.UTC),
+		Level:   WarningLevel,
+		Message: "message with \"quotes\"\nand newline",
+		Fields:  fields,
+	}
+
+	line := (&LogfmtEncoder{}).Encode(record)
+	if strings.ContainsAny(line, "\n\r") {
+		t.Fatalf("Encoded line should not contain raw line breaks: %q", line)
+	}
+
+	pairs, err := ParseLogfmt(line)
+	if err != nil {
+		t.Fatalf("ParseLogfmt() error = %v for line %q", err, line)
+	}
+
+	parsed := map[string]string{}
+	for _, pair := range pairs {
+		parsed[pair.Key] = pair.Value
+	}
+
+	if parsed["time"] != "2024-12-13T15:30:45.123Z" || parsed["level"] != "warning" || parsed["msg"] != record.Message {
+		t.Errorf("Record keys did not round-trip: %v", parsed)
+	}
+	for k, v := range fields {
+		if parsed[k] != v {
+			t.Errorf("Field %q did not round-trip: got %q, want %q", k, parsed[k], v)
+		}
+	}
+
+	formatted := (&LogfmtFormatter{}).Format(fields)
+	pairs, err = ParseLogfmt(formatted)
+	if err != nil {
+		t.Fatalf("ParseLogfmt() error = %v for formatter output %q", err, formatted)
+	}
+	if len(pairs) != len(fields) {
+		t.Fatalf("Expected %d pairs from formatter output, got %d", len(fields), len(pairs))
+	}
+	for _, pair := range pairs {
+		if fields[pair.Key] != pair.Value {
+			t.Errorf("Formatter field %q did not round-trip: got %q, want %q", pair.Key, pair.Value, fields[pair.Key])
+		}
+	}
+}
+
+func TestLogger_WithLogfmtEncoder(t *testing.T) {
+	mockWriter := &MockWriter{}
+	logger := New(InfoLevel, mockWriter).WithEncoder(&LogfmtEncoder{}).WithField("user", "john doe")
+
+	logger.Info("User logged in")
+
+	output := mockWriter.String()
+	if !strings.Contains(output, `level=info msg="User logged in" user="john doe"`) {
+		t.Errorf("Unexpected logfmt output: %q", output)
+	}
+}
@@ -0,0 +1,342 @@
+package balogan
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Default Loki writer settings.
+const (
+	// DefaultLokiURL is the push endpoint of a local Loki.
+	DefaultLokiURL = "http://localhost:3100/loki/api/v1/push"
+	// DefaultLokiLevelLabel is the label holding the record's level.
+	DefaultLokiLevelLabel = "level"
+	// DefaultLokiMaxLabelValues is the number of distinct values a field label may take.
+	DefaultLokiMaxLabelValues = 100
+	// DefaultLokiBatchSize is the maximum number of entries per push request.
+	DefaultLokiBatchSize = 1000
+	// DefaultLokiFlushInterval is how often pending entries are pushed.
+	DefaultLokiFlushInterval = time.Second
+	// DefaultLokiTimeout limits every push request.
+	DefaultLokiTimeout = 10 * time.Second
+	// DefaultLokiMaxRetries is the number of retries of a failed push.
+	DefaultLokiMaxRetries = 5
+	// DefaultLokiRetryBackoff is the delay before the first retry.
+	DefaultLokiRetryBackoff = 500 * time.Millisecond
+	// DefaultLokiMaxBackoff caps the delay between retries.
+	DefaultLokiMaxBackoff = 30 * time.Second
+)
+
+// LokiConfig configures a LokiWriter.
+type LokiConfig struct {
+	// URL is the push endpoint. DefaultLokiURL is used when empty.
+	URL string
+	// TenantID is sent in the X-Scope-OrgID header for multi-tenant Loki.
+	TenantID string
+	// Headers are added to every request, for example for authentication.
+	Headers map[string]string
+	// Compress gzips request bodies.
+	Compress bool
+
+	// Labels are static labels added to every stream, for example {"job": "shop"}.
+	Labels map[string]string
+	// LabelFields lists the field keys which become labels. Other fields stay in the line.
+	LabelFields []string
+	// LevelLabel is the name of the level label. DefaultLokiLevelLabel is used when empty,
+	// "-" disables the label.
+	LevelLabel string
+	// MaxLabelValues caps the distinct values of every field label. Once a label has
+	// reached the cap, records with new values keep the field in the line instead.
+	// DefaultLokiMaxLabelValues is used when zero.
+	MaxLabelValues int
+
+	// Encoder encodes the line from the record without its label fields.
+	// A LogfmtEncoder is used when nil.
+	Encoder Encoder
+
+	// BatchSize is the maximum number of entries per request. DefaultLokiBatchSize is used when zero.
+	BatchSize int
+	// FlushInterval is how often pending entries are pushed. DefaultLokiFlushInterval is used when zero.
+	FlushInterval time.Duration
+	// Timeout limits every request. DefaultLokiTimeout is used when zero.
+	Timeout time.Duration
+	// MaxRetries is the number of retries of a failed request. DefaultLokiMaxRetries
+	// is used when zero, a negative value disables retries.
+	MaxRetries int
+	// RetryBackoff is the delay before the first retry, doubled for every further retry
+	// up to MaxBackoff. DefaultLokiRetryBackoff and DefaultLokiMaxBackoff are used when zero.
+	RetryBackoff time.Duration
+	MaxBackoff   time.Duration
+
+	// Client sends the requests. http.DefaultClient is used when nil.
+	Client *http.Client
+	// ErrorHandler receives errors of background pushes and label cardinality warnings.
+	// When nil, push errors are returned by the next WriteRecord call.
+	ErrorHandler ErrorHandler
+}
+
+// LokiWriter pushes records to Grafana Loki through the /loki/api/v1/push JSON API.
+//
+// Every record is assigned to a stream by its labels: the static Labels, the level
+// and the fields listed in LabelFields. The remaining fields are encoded into the
+// line. Entries are batched and pushed when BatchSize entries are pending, every
+// FlushInterval, on Flush and on Close. Network errors, 429 and 5xx responses are
+// retried with exponential backoff.
+//
+// To protect Loki from high cardinality streams, every field label accepts at most
+// MaxLabelValues distinct values. Further values are kept in the line.
+type LokiWriter struct {
+	cfg     LokiConfig
+	retry   retryPolicy
+	batcher *batcher[lokiEntry]
+
+	mu          sync.Mutex
+	labelValues map[string]map[string]struct{}
+	capped      map[string]bool
+}
+
+type lokiEntry struct {
+	labels map[string]string
+	time   time.Time
+	line   string
+}
+
+// NewLokiWriter creates a new LokiWriter.
+func NewLokiWriter(cfg *LokiConfig) (*LokiWriter, error) {
+	c := LokiConfig{}
+	if cfg != nil {
+		c = *cfg
+	}
+
+	if c.URL == "" {
+		c.URL = DefaultLokiURL
+	}
+	if u, err := url.Parse(c.URL); err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("loki: invalid url %q", c.URL)
+	}
+	if c.LevelLabel == "" {
+		c.LevelLabel = DefaultLokiLevelLabel
+	}
+	if c.MaxLabelValues <= 0 {
+		c.MaxLabelValues = DefaultLokiMaxLabelValues
+	}
+	if c.Encoder == nil {
+		c.Encoder = &LogfmtEncoder{}
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = DefaultLokiBatchSize
+	}
+	if c.FlushInterval <= 0 {
+		c.FlushInterval = DefaultLokiFlushInterval
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = DefaultLokiTimeout
+	}
+	if c.MaxRetries == 0 {
+		c.MaxRetries = DefaultLokiMaxRetries
+	}
+	if c.RetryBackoff <= 0 {
+		c.RetryBackoff = DefaultLokiRetryBackoff
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = DefaultLokiMaxBackoff
+	}
+	if c.Client == nil {
+		c.Client = http.DefaultClient
+	}
+
+	labels := make(map[string]string, len(c.Labels))
+	for k, v := range c.Labels {
+		labels[lokiLabelName(k)] = v
+	}
+	c.Labels = labels
+
+	w := &LokiWriter{
+		cfg:         c,
+		retry:       retryPolicy{maxRetries: c.MaxRetries, backoff: c.RetryBackoff, maxBackoff: c.MaxBackoff, jitter: true},
+		labelValues: make(map[string]map[string]struct{}),
+		capped:      make(map[string]bool),
+	}
+	w.batcher = newBatcher(c.BatchSize, c.FlushInterval, c.ErrorHandler, w.push)
+	return w, nil
+}
+
+// Write queues bytes as the line of an INFO level entry.
+func (w *LokiWriter) Write(bytes []byte) (int, error) {
+	record := &Record{Time: time.Now(), Level: InfoLevel, Message: string(bytes)}
+	if err := w.WriteRecord(record, bytes); err != nil {
+		return 0, err
+	}
+	return len(bytes), nil
+}
+
+// WriteRecord assigns the record to a stream and queues it for pushing.
+func (w *LokiWriter) WriteRecord(record *Record, line []byte) error {
+	labels := make(map[string]string, len(w.cfg.Labels)+len(w.cfg.LabelFields)+1)
+	for k, v := range w.cfg.Labels {
+		labels[k] = v
+	}
+	if w.cfg.LevelLabel != "-" {
+		labels[lokiLabelName(w.cfg.LevelLabel)] = strings.ToLower(record.Level.String())
+	}
+
+	lineRecord := *record
+	lineRecord.Fields = record.Fields.Copy()
+	for _, key := range w.cfg.LabelFields {
+		value, ok := record.Fields[key]
+		if !ok {
+			continue
+		}
+		name := lokiLabelName(key)
+		if s := logfmtString(value); w.allowLabelValue(name, s) {
+			labels[name] = s
+			delete(lineRecord.Fields, key)
+		}
+	}
+
+	return w.batcher.add(lokiEntry{
+		labels: labels,
+		time:   record.Time,
+		line:   w.cfg.Encoder.Encode(&lineRecord),
+	})
+}
+
+// Flush pushes all pending entries.
+func (w *LokiWriter) Flush() error {
+	return w.batcher.flush()
+}
+
+// Close pushes all pending entries and stops the writer.
+func (w *LokiWriter) Close() error {
+	return w.batcher.close()
+}
+
+// allowLabelValue reports whether value may be used for the label without
+// exceeding MaxLabelValues. The first time the cap is hit it is reported
+// to the error handler.
+func (w *LokiWriter) allowLabelValue(label, value string) bool {
+	w.mu.Lock()
+	values, ok := w.labelValues[label]
+	if !ok {
+		values = make(map[string]struct{})
+		w.labelValues[label] = values
+	}
+	if _, seen := values[value]; seen {
+		w.mu.Unlock()
+		return true
+	}
+	if len(values) < w.cfg.MaxLabelValues {
+		values[value] = struct{}{}
+		w.mu.Unlock()
+		return true
+	}
+	report := !w.capped[label]
+	w.capped[label] = true
+	w.mu.Unlock()
+
+	if report && w.cfg.ErrorHandler != nil {
+		w.cfg.ErrorHandler.Handle(fmt.Errorf("loki: label %q reached %d values, new values are kept in the line", label, w.cfg.MaxLabelValues))
+	}
+	return false
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+type lokiPushRequest struct {
+	Streams []*lokiStream `json:"streams"`
+}
+
+func (w *LokiWriter) push(entries []lokiEntry) error {
+	streams := make(map[string]*lokiStream)
+	var order []string
+	for _, entry := range entries {
+		key := lokiStreamKey(entry.labels)
+		stream, ok := streams[key]
+		if !ok {
+			stream = &lokiStream{Stream: entry.labels}
+			streams[key] = stream
+			order = append(order, key)
+		}
+		stream.Values = append(stream.Values, [2]string{strconv.FormatInt(entry.time.UnixNano(), 10), entry.line})
+	}
+
+	req := lokiPushRequest{Streams: make([]*lokiStream, 0, len(order))}
+	for _, key := range order {
+		req.Streams = append(req.Streams, streams[key])
+	}
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("loki: %w", err)
+	}
+
+	headers := map[string]string{"Content-Type": "application/json"}
+	if w.cfg.TenantID != "" {
+		headers["X-Scope-OrgID"] = w.cfg.TenantID
+	}
+	for k, v := range w.cfg.Headers {
+		headers[k] = v
+	}
+	httpReq := httpRequest{method: http.MethodPost, url: w.cfg.URL, headers: headers, body: body, gzip: w.cfg.Compress}
+
+	err = w.retry.do(context.Background(), func() error {
+		_, err := httpReq.send(context.Background(), w.cfg.Client, w.cfg.Timeout)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("loki: pushing %d entries: %w", len(entries), err)
+	}
+	return nil
+}
+
+// lokiStreamKey returns a stable identifier of a label set.
+func lokiStreamKey(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		b.WriteString(k)
+		b.WriteByte('=')
+		b.WriteString(strconv.Quote(labels[k]))
+		b.WriteByte(',')
+	}
+	return b.String()
+}
+
+// lokiLabelName converts a field key into a valid Loki label name
+// matching [a-zA-Z_][a-zA-Z0-9_]*.
+func lokiLabelName(key string) string {
+	var b strings.Builder
+	for i, r := range key {
+		switch {
+		case r == '_', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+			b.WriteRune(r)
+		case r >= '0' && r <= '9':
+			if i == 0 {
+				b.WriteByte('_')
+			}
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	if b.Len() == 0 {
+		return "_"
+	}
+	return b.String()
+}
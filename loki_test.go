@@ -0,0 +1,222 @@
+package balogan
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func decodeLokiPush(body []byte) (lokiPushRequest, error) {
+	var req lokiPushRequest
+	err := json.Unmarshal(body, &req)
+	return req, err
+}
+
+// lokiStreams returns the streams of the pushes the server accepted.
+func lokiStreams(server *recordingServer[lokiPushRequest]) []*lokiStream {
+	var streams []*lokiStream
+	for _, req := range server.accepted() {
+		streams = append(streams, req.Streams...)
+	}
+	return streams
+}
+
+func TestLokiWriter_Streams(t *testing.T) {
+	server := newRecordingServer(t, decodeLokiPush)
+	writer, err := NewLokiWriter(&LokiConfig{
+		URL:           server.URL + "/loki/api/v1/push",
+		FlushInterval: time.Hour,
+		TenantID:      "team-a",
+		Compress:      true,
+		Labels:        map[string]string{"job": "shop"},
+		LabelFields:   []string{"service"},
+	})
+	if err != nil {
+		t.Fatalf("NewLokiWriter failed: %v", err)
+	}
+
+	logger := New(InfoLevel, writer)
+	logger.WithFields(Fields{"service": "cart", "user_id": 7}).Info("added item")
+	logger.WithFields(Fields{"service": "cart", "user_id": 8}).Info("removed item")
+	logger.WithField("service", "payment").Error("card declined")
+
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	req := server.captured()[0]
+	if req.path != "/loki/api/v1/push" {
+		t.Errorf("Expected a push to the configured URL, got %q", req.path)
+	}
+	if got := req.header.Get("X-Scope-OrgID"); got != "team-a" {
+		t.Errorf("Expected tenant header, got %q", got)
+	}
+
+	streams := lokiStreams(server)
+	if len(streams) != 2 {
+		t.Fatalf("Expected 2 streams, got %d", len(streams))
+	}
+
+	cart := streams[0]
+	if cart.Stream["job"] != "shop" || cart.Stream["service"] != "cart" || cart.Stream["level"] != "info" {
+		t.Errorf("Unexpected labels %v", cart.Stream)
+	}
+	if _, ok := cart.Stream["user_id"]; ok {
+		t.Error("Fields which are not label fields should not be labels")
+	}
+	if len(cart.Values) != 2 {
+		t.Fatalf("Expected 2 entries in the cart stream, got %d", len(cart.Values))
+	}
+	line := cart.Values[0][1]
+	if !strings.Contains(line, `msg="added item"`) || !strings.Contains(line, "user_id=7") {
+		t.Errorf("Expected message and remaining fields in the line, got %q", line)
+	}
+	if strings.Contains(line, "service=") {
+		t.Errorf("Label fields should be removed from the line, got %q", line)
+	}
+
+	if streams[1].Stream["service"] != "payment" || streams[1].Stream["level"] != "error" {
+		t.Errorf("Unexpected labels %v", streams[1].Stream)
+	}
+}
+
+func TestLokiWriter_LabelCardinalityCap(t *testing.T) {
+	server := newRecordingServer(t, decodeLokiPush)
+	var warnings []error
+	writer, err := NewLokiWriter(&LokiConfig{
+		URL:            server.URL + "/loki/api/v1/push",
+		FlushInterval:  time.Hour,
+		LabelFields:    []string{"user_id"},
+		LevelLabel:     "-",
+		MaxLabelValues: 2,
+		ErrorHandler:   ErrorHandlerFunc(func(err error) { warnings = append(warnings, err) }),
+	})
+	if err != nil {
+		t.Fatalf("NewLokiWriter failed: %v", err)
+	}
+
+	logger := New(InfoLevel, writer)
+	for _, id := range []int{1, 2, 3, 4, 1} {
+		logger.WithField("user_id", id).Info("login")
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	streams := lokiStreams(server)
+	if len(streams) != 3 {
+		t.Fatalf("Expected streams for 2 label values and 1 without the label, got %d", len(streams))
+	}
+	for _, stream := range streams {
+		if _, ok := stream.Stream["level"]; ok {
+			t.Error("Level label should be disabled")
+		}
+		if _, ok := stream.Stream["user_id"]; !ok {
+			for _, v := range stream.Values {
+				if !strings.Contains(v[1], "user_id=") {
+					t.Errorf("Capped field should be kept in the line, got %q", v[1])
+				}
+			}
+		}
+	}
+	if len(warnings) != 1 {
+		t.Errorf("Expected one cardinality warning, got %v", warnings)
+	}
+}
+
+func TestLokiWriter_Retry(t *testing.T) {
+	server := newRecordingServer(t, decodeLokiPush)
+	server.failures = 2
+	writer, err := NewLokiWriter(&LokiConfig{
+		URL:           server.URL + "/loki/api/v1/push",
+		FlushInterval: time.Hour,
+		RetryBackoff:  time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("NewLokiWriter failed: %v", err)
+	}
+	defer writer.Close()
+
+	New(InfoLevel, writer).Info("retried")
+	if err := writer.Flush(); err != nil {
+		t.Fatalf("Flush should succeed after retries, got %v", err)
+	}
+	if len(lokiStreams(server)) != 1 {
+		t.Error("Expected the entry to be pushed after retries")
+	}
+}
+
+func TestLokiWriter_RetriesExhausted(t *testing.T) {
+	server := newRecordingServer(t, decodeLokiPush)
+	server.failures = 10
+	server.status = http.StatusTooManyRequests
+	writer, err := NewLokiWriter(&LokiConfig{
+		URL:           server.URL + "/loki/api/v1/push",
+		FlushInterval: time.Hour,
+		MaxRetries:    1,
+		RetryBackoff:  time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("NewLokiWriter failed: %v", err)
+	}
+	defer writer.Close()
+
+	New(InfoLevel, writer).Info("dropped")
+	err = writer.Flush()
+
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("Expected HTTP 429 error, got %v", err)
+	}
+	if got := len(server.captured()); got != 2 {
+		t.Errorf("Expected 2 attempts, got %d", got)
+	}
+}
+
+func TestLokiWriter_Encoder(t *testing.T) {
+	server := newRecordingServer(t, decodeLokiPush)
+	writer, err := NewLokiWriter(&LokiConfig{
+		URL:           server.URL + "/loki/api/v1/push",
+		FlushInterval: time.Hour,
+		Encoder:       &JSONEncoder{},
+	})
+	if err != nil {
+		t.Fatalf("NewLokiWriter failed: %v", err)
+	}
+
+	New(InfoLevel, writer).WithField("k", "v").Info("json line")
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	var line map[string]interface{}
+	if err := json.Unmarshal([]byte(lokiStreams(server)[0].Values[0][1]), &line); err != nil {
+		t.Fatalf("Expected a JSON line: %v", err)
+	}
+	if line["k"] != "v" {
+		t.Errorf("Unexpected line %v", line)
+	}
+}
+
+func TestLokiLabelName(t *testing.T) {
+	tests := map[string]string{
+		"service":    "service",
+		"http.route": "http_route",
+		"9lives":     "_9lives",
+		"":           "_",
+	}
+	for key, expected := range tests {
+		if got := lokiLabelName(key); got != expected {
+			t.Errorf("lokiLabelName(%q) = %q, want %q", key, got, expected)
+		}
+	}
+}
+
+func TestNewLokiWriter_InvalidURL(t *testing.T) {
+	if _, err := NewLokiWriter(&LokiConfig{URL: "loki:3100"}); err == nil {
+		t.Error("Expected error for url without scheme")
+	}
+}
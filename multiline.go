@@ -0,0 +1,138 @@
+package balogan
+
+import (
+	"strings"
+	"sync/atomic"
+)
+
+// MultilinePolicy selects how a logger writes messages containing newlines,
+// which break line-oriented collectors when written verbatim.
+//
+// The policy applies to the encoded line, so it behaves the same for every
+// fields formatter and encoder. Encoders which already escape newlines, such
+// as JSONEncoder, produce single lines and need no policy.
+// Except with MultilineSplit, writers receiving the structured Record see the
+// original message.
+type MultilinePolicy int
+
+const (
+	// MultilineVerbatim writes newlines unchanged. This is the default.
+	MultilineVerbatim MultilinePolicy = iota
+	// MultilineEscape replaces newlines with the two characters `\n` and
+	// carriage returns with `\r`. Backslashes are written as `\\` on every
+	// line, so a literal `\n` in a message is told apart from a newline and
+	// every line decodes back exactly.
+	MultilineEscape
+	// MultilineIndent starts every continuation line with MultilineMarker,
+	// so collectors can join the lines back into one message.
+	MultilineIndent
+	// MultilineSplit writes every line of the message as a separate record.
+	// The records share a sequence ID in the MultilineIDFieldKey field and carry
+	// their position in MultilinePartFieldKey. Newlines elsewhere, for example
+	// in field values, are escaped.
+	MultilineSplit
+)
+
+// MultilineMarker starts continuation lines with MultilineIndent.
+const MultilineMarker = "  | "
+
+// Field keys added by MultilineSplit.
+const (
+	MultilineIDFieldKey   = "multiline_id"
+	MultilinePartFieldKey = "multiline_part"
+)
+
+// multilineSequence generates the sequence IDs of split messages.
+var multilineSequence atomic.Uint64
+
+var (
+	multilineEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`, "\r", `\r`)
+	multilineIndenter = strings.NewReplacer("\r\n", "\n"+MultilineMarker, "\n", "\n"+MultilineMarker)
+)
+
+// WithMultiline returns a new Logger instance with the given multiline policy.
+//
+// Parameters:
+//
+//	policy: How messages containing newlines are written.
+//
+// Example:
+//
+//	logger := New(InfoLevel, writer).WithMultiline(MultilineIndent)
+//	logger.Info("query failed:\nSELECT *\nFROM users")
+//	// INFO query failed:
+//	//   | SELECT *
+//	//   | FROM users
+func (l *Logger) WithMultiline(policy MultilinePolicy) *Logger {
+	return &Logger{
+		level:             l.level,
+		writers:           l.writers,
+		prefixes:          l.prefixes,
+		errorHandler:      l.errorHandler,
+		concurrency:       l.concurrency,
+		fields:            l.fields.Copy(),
+		fieldsFormatter:   l.fieldsFormatter,
+		conditions:        l.conditions,
+		levelConditions:   l.levelConditions,
+		contextConditions: l.contextConditions,
+		exitFunc:          l.exitFunc,
+		exitCode:          l.exitCode,
+		exitTimeout:       l.exitTimeout,
+		exitHandlers:      l.exitHandlers,
+		repanic:           l.repanic,
+		beforeHooks:       l.beforeHooks,
+		afterHooks:        l.afterHooks,
+		processors:        l.processors,
+		redactor:          l.redactor,
+		encoder:           l.encoder,
+		name:              l.name,
+		reportCaller:      l.reportCaller,
+		ctx:               l.ctx,
+		multiline:         policy,
+	}
+}
+
+// apply rewrites the newlines of an encoded line according to the policy.
+// A trailing newline is kept, as writers use it as the line terminator.
+func (p MultilinePolicy) apply(line string) string {
+	if p == MultilineVerbatim {
+		return line
+	}
+	body, terminator := line, ""
+	if strings.HasSuffix(line, "\n") {
+		body, terminator = line[:len(line)-1], "\n"
+	}
+	if p == MultilineEscape {
+		return multilineEscaper.Replace(body) + terminator
+	}
+	if !strings.ContainsAny(body, "\r\n") {
+		return line
+	}
+
+	if p == MultilineIndent {
+		return multilineIndenter.Replace(body) + terminator
+	}
+	return multilineEscaper.Replace(body) + terminator
+}
+
+// splitRecord splits the message of record into one record per line.
+// Trailing newlines are dropped.
+func splitRecord(record *Record) []*Record {
+	message := strings.TrimRight(record.Message, "\r\n")
+	lines := strings.Split(strings.ReplaceAll(message, "\r\n", "\n"), "\n")
+	id := multilineSequence.Add(1)
+
+	parts := make([]*Record, len(lines))
+	for i, line := range lines {
+		part := *record
+		part.Message = line
+		part.Fields = record.Fields.Copy()
+		if part.Fields == nil {
+			part.Fields = make(Fields)
+		}
+		part.Fields[MultilineIDFieldKey] = id
+		part.Fields[MultilinePartFieldKey] = i + 1
+		parts[i] = &part
+	}
+	return parts
+}
@@ -0,0 +1,157 @@
+package balogan
+
+import (
+	"strings"
+	"testing"
+)
+
+// lineWriter records every written line and record.
+type lineWriter struct {
+	lines   []string
+	records []*Record
+}
+
+func (w *lineWriter) Write(bytes []byte) (int, error) {
+	w.lines = append(w.lines, string(bytes))
+	return len(bytes), nil
+}
+
+func (w *lineWriter) WriteRecord(record *Record, line []byte) error {
+	w.records = append(w.records, record)
+	_, err := w.Write(line)
+	return err
+}
+
+func (w *lineWriter) Close() error {
+	return nil
+}
+
+func TestLogger_WithMultiline(t *testing.T) {
+	message := "query failed:\nSELECT *\r\nFROM users"
+
+	tests := []struct {
+		name     string
+		policy   MultilinePolicy
+		expected string
+	}{
+		{"verbatim", MultilineVerbatim, "INFO " + message},
+		{"escape", MultilineEscape, `INFO query failed:\nSELECT *\r\nFROM users`},
+		{"indent", MultilineIndent, "INFO query failed:\n  | SELECT *\n  | FROM users"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writer := &lineWriter{}
+			logger := New(InfoLevel, writer).WithMultiline(tt.policy)
+			logger.Info(message)
+
+			if len(writer.lines) != 1 || writer.lines[0] != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, writer.lines)
+			}
+			if writer.records[0].Message != message {
+				t.Errorf("Record message should stay unchanged, got %q", writer.records[0].Message)
+			}
+		})
+	}
+}
+
+func TestLogger_WithMultiline_EscapeRoundTrip(t *testing.T) {
+	messages := []string{
+		`literal \n without newline`,
+		"real\nnewline",
+		`C:\new\path` + "\r\n" + `literal \n and \\`,
+	}
+	writer := &lineWriter{}
+	logger := New(InfoLevel, writer).WithMultiline(MultilineEscape)
+	for _, message := range messages {
+		logger.Info(message)
+	}
+
+	expected := []string{
+		`INFO literal \\n without newline`,
+		`INFO real\nnewline`,
+		`INFO C:\\new\\path\r\nliteral \\n and \\\\`,
+	}
+	if len(writer.lines) != len(expected) {
+		t.Fatalf("Expected %d lines, got %q", len(expected), writer.lines)
+	}
+	unescaper := strings.NewReplacer(`\\`, `\`, `\n`, "\n", `\r`, "\r")
+	for i, line := range writer.lines {
+		if line != expected[i] {
+			t.Errorf("Expected %q, got %q", expected[i], line)
+		}
+		if decoded := unescaper.Replace(strings.TrimPrefix(line, "INFO ")); decoded != messages[i] {
+			t.Errorf("Expected the escaped line to decode to %q, got %q", messages[i], decoded)
+		}
+	}
+}
+
+func TestLogger_WithMultiline_Formatters(t *testing.T) {
+	formats := map[string]func(*Logger) *Logger{
+		"key-value": func(l *Logger) *Logger { return l },
+		"logfmt":    (*Logger).WithLogfmt,
+		"json":      (*Logger).WithJSON,
+		"encoder":   func(l *Logger) *Logger { return l.WithEncoder(&JSONEncoder{}) },
+	}
+
+	for name, format := range formats {
+		t.Run(name, func(t *testing.T) {
+			writer := &lineWriter{}
+			logger := format(New(InfoLevel, writer).WithMultiline(MultilineEscape))
+			logger.WithField("stack", "main.go:1\nmain.go:2").Error("first\nsecond")
+
+			if strings.Contains(writer.lines[0], "\n") {
+				t.Errorf("Expected a single line, got %q", writer.lines[0])
+			}
+		})
+	}
+}
+
+func TestLogger_WithMultiline_Split(t *testing.T) {
+	writer := &lineWriter{}
+	logger := New(InfoLevel, writer).WithMultiline(MultilineSplit).WithField("stack", "a\nb")
+
+	logger.Error("panic: boom\ngoroutine 1\n")
+	logger.Error("single line")
+	logger.Error("another\nmessage")
+
+	if len(writer.records) != 5 {
+		t.Fatalf("Expected 5 records, got %d: %q", len(writer.records), writer.lines)
+	}
+
+	first, second := writer.records[0], writer.records[1]
+	if first.Message != "panic: boom" || second.Message != "goroutine 1" {
+		t.Errorf("Unexpected split messages %q, %q", first.Message, second.Message)
+	}
+	if first.Fields[MultilineIDFieldKey] != second.Fields[MultilineIDFieldKey] {
+		t.Error("Parts of one message should share the sequence ID")
+	}
+	if first.Fields[MultilinePartFieldKey] != 1 || second.Fields[MultilinePartFieldKey] != 2 {
+		t.Errorf("Unexpected part numbers %v, %v", first.Fields[MultilinePartFieldKey], second.Fields[MultilinePartFieldKey])
+	}
+	if _, ok := writer.records[2].Fields[MultilineIDFieldKey]; ok {
+		t.Error("Single line messages should not get a sequence ID")
+	}
+	if writer.records[3].Fields[MultilineIDFieldKey] == first.Fields[MultilineIDFieldKey] {
+		t.Error("Every message should get its own sequence ID")
+	}
+
+	for _, line := range writer.lines {
+		if strings.Contains(line, "\n") {
+			t.Errorf("Expected newlines in fields to be escaped, got %q", line)
+		}
+	}
+	if _, ok := logger.GetFields()[MultilineIDFieldKey]; ok {
+		t.Error("Logger fields should not be modified")
+	}
+}
+
+func TestNewFromConfig_Multiline(t *testing.T) {
+	writer := &lineWriter{}
+	logger := NewFromConfig(&BaloganConfig{Level: InfoLevel, Writers: []LogWriter{writer}, Multiline: MultilineIndent})
+	logger.Info("a\nb")
+
+	if writer.lines[0] != "INFO a\n  | b" {
+		t.Errorf("Unexpected line %q", writer.lines[0])
+	}
+}
@@ -0,0 +1,246 @@
+package balogan
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// NetworkFraming selects how messages are delimited on a network connection.
+type NetworkFraming int
+
+const (
+	// FramingNewline terminates every message with '\n'.
+	FramingNewline NetworkFraming = iota
+	// FramingLengthPrefix prefixes every message with its length as a 4 byte big-endian integer.
+	FramingLengthPrefix
+)
+
+// Default network writer settings.
+const (
+	// DefaultNetworkDialTimeout limits connection setup.
+	DefaultNetworkDialTimeout = 5 * time.Second
+	// DefaultNetworkMinBackoff is the delay before the first reconnection attempt.
+	DefaultNetworkMinBackoff = 100 * time.Millisecond
+	// DefaultNetworkMaxBackoff caps the delay between reconnection attempts.
+	DefaultNetworkMaxBackoff = 30 * time.Second
+)
+
+// ErrNetworkReconnecting is returned by writes while a NetworkLogWriter waits
+// for the backoff delay before reconnecting. The message is dropped.
+var ErrNetworkReconnecting = errors.New("network writer: waiting to reconnect")
+
+// NetworkConfig configures a NetworkLogWriter.
+type NetworkConfig struct {
+	// Network is "tcp", "tcp4", "tcp6", "udp", "udp4", "udp6", "unix" or "unixgram".
+	Network string
+	// Address is the remote address or socket path.
+	Address string
+
+	// Framing delimits messages. FramingNewline is used by default.
+	// Over datagram networks every message is sent in its own datagram as well.
+	Framing NetworkFraming
+	// TLS enables TLS for TCP connections.
+	TLS *tls.Config
+
+	// DialTimeout limits connection setup. DefaultNetworkDialTimeout is used when zero.
+	DialTimeout time.Duration
+	// WriteTimeout sets a deadline for every write. No deadline is set when zero.
+	WriteTimeout time.Duration
+
+	// MinBackoff and MaxBackoff bound the delay between reconnection attempts,
+	// which doubles after every failed attempt. DefaultNetworkMinBackoff and
+	// DefaultNetworkMaxBackoff are used when zero.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+// NetworkLogWriter writes log messages to a TCP, UDP or unix socket.
+//
+// The connection is dialed lazily on the first write. When a write fails, the
+// connection is re-established once and the write retried. While the remote end
+// stays unreachable, writes fail fast with ErrNetworkReconnecting and dial attempts
+// are spaced with exponential backoff, so logging never blocks on a dead server.
+type NetworkLogWriter struct {
+	mu       sync.Mutex
+	cfg      NetworkConfig
+	conn     net.Conn
+	closed   bool
+	backoff  time.Duration
+	nextDial time.Time
+	lastErr  error
+}
+
+// NewNetworkLogWriter creates a new NetworkLogWriter for the given network and address.
+//
+// Parameters:
+//
+//	network: "tcp", "udp" or "unix" (and their variants, see NetworkConfig).
+//	address: The remote address or socket path.
+//
+// Example:
+//
+//	writer, err := NewNetworkLogWriter("tcp", "logstash:5000")
+//	logger := New(InfoLevel, writer)
+func NewNetworkLogWriter(network, address string) (*NetworkLogWriter, error) {
+	return NewNetworkLogWriterFromConfig(&NetworkConfig{Network: network, Address: address})
+}
+
+// NewNetworkLogWriterFromConfig creates a new NetworkLogWriter from a NetworkConfig.
+// No connection is established until the first write.
+func NewNetworkLogWriterFromConfig(cfg *NetworkConfig) (*NetworkLogWriter, error) {
+	if cfg == nil || cfg.Address == "" {
+		return nil, errors.New("network writer: address is required")
+	}
+
+	c := *cfg
+	switch c.Network {
+	case "tcp", "tcp4", "tcp6":
+	case "udp", "udp4", "udp6", "unix", "unixgram":
+		if c.TLS != nil {
+			return nil, fmt.Errorf("network writer: TLS is not supported over %q", c.Network)
+		}
+	default:
+		return nil, fmt.Errorf("network writer: unsupported network %q", c.Network)
+	}
+	if c.Framing != FramingNewline && c.Framing != FramingLengthPrefix {
+		return nil, fmt.Errorf("network writer: unsupported framing %d", c.Framing)
+	}
+	if c.DialTimeout <= 0 {
+		c.DialTimeout = DefaultNetworkDialTimeout
+	}
+	if c.MinBackoff <= 0 {
+		c.MinBackoff = DefaultNetworkMinBackoff
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = DefaultNetworkMaxBackoff
+	}
+
+	return &NetworkLogWriter{cfg: c}, nil
+}
+
+func (w *NetworkLogWriter) Write(bytes []byte) (int, error) {
+	frame := w.frame(bytes)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return 0, os.ErrClosed
+	}
+
+	if w.conn != nil {
+		if err := w.send(frame); err == nil {
+			return len(bytes), nil
+		}
+		// The connection is broken, reconnect once.
+		_ = w.conn.Close()
+		w.conn = nil
+	}
+
+	if err := w.connect(); err != nil {
+		return 0, err
+	}
+	if err := w.send(frame); err != nil {
+		_ = w.conn.Close()
+		w.conn = nil
+		w.scheduleReconnect(err)
+		return 0, err
+	}
+	return len(bytes), nil
+}
+
+func (w *NetworkLogWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return os.ErrClosed
+	}
+	w.closed = true
+
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+// connect dials the remote end unless the backoff delay has not passed yet.
+func (w *NetworkLogWriter) connect() error {
+	if now := time.Now(); now.Before(w.nextDial) {
+		return fmt.Errorf("%w: %w", ErrNetworkReconnecting, w.lastErr)
+	}
+
+	dialer := &net.Dialer{Timeout: w.cfg.DialTimeout}
+
+	var conn net.Conn
+	var err error
+	if w.cfg.TLS != nil {
+		conn, err = tls.DialWithDialer(dialer, w.cfg.Network, w.cfg.Address, w.tlsConfig())
+	} else {
+		conn, err = dialer.Dial(w.cfg.Network, w.cfg.Address)
+	}
+	if err != nil {
+		w.scheduleReconnect(err)
+		return err
+	}
+
+	w.conn = conn
+	w.backoff = 0
+	w.nextDial = time.Time{}
+	return nil
+}
+
+// scheduleReconnect delays the next dial with exponential backoff.
+func (w *NetworkLogWriter) scheduleReconnect(err error) {
+	if w.backoff == 0 {
+		w.backoff = w.cfg.MinBackoff
+	} else {
+		w.backoff = min(w.backoff*2, w.cfg.MaxBackoff)
+	}
+	w.nextDial = time.Now().Add(w.backoff)
+	w.lastErr = err
+}
+
+// tlsConfig returns the TLS configuration with ServerName set from the address when missing.
+func (w *NetworkLogWriter) tlsConfig() *tls.Config {
+	if w.cfg.TLS.ServerName != "" {
+		return w.cfg.TLS
+	}
+	cfg := w.cfg.TLS.Clone()
+	if host, _, err := net.SplitHostPort(w.cfg.Address); err == nil {
+		cfg.ServerName = host
+	}
+	return cfg
+}
+
+func (w *NetworkLogWriter) send(frame []byte) error {
+	if w.cfg.WriteTimeout > 0 {
+		_ = w.conn.SetWriteDeadline(time.Now().Add(w.cfg.WriteTimeout))
+	}
+	_, err := w.conn.Write(frame)
+	return err
+}
+
+func (w *NetworkLogWriter) frame(message []byte) []byte {
+	if w.cfg.Framing == FramingLengthPrefix {
+		frame := make([]byte, 4, 4+len(message))
+		binary.BigEndian.PutUint32(frame, uint32(len(message)))
+		return append(frame, message...)
+	}
+
+	if bytes.HasSuffix(message, []byte("\n")) {
+		return message
+	}
+	frame := make([]byte, 0, len(message)+1)
+	frame = append(frame, message...)
+	return append(frame, '\n')
+}
@@ -0,0 +1,296 @@
+package balogan
+
+import (
+	"bufio"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// acceptLines accepts connections on l and sends every received line to the returned channel.
+func acceptLines(t *testing.T, l net.Listener) (<-chan string, <-chan net.Conn) {
+	lines := make(chan string, 100)
+	conns := make(chan net.Conn, 10)
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			conns <- conn
+			go func() {
+				scanner := bufio.NewScanner(conn)
+				for scanner.Scan() {
+					lines <- scanner.Text()
+				}
+			}()
+		}
+	}()
+	t.Cleanup(func() { l.Close() })
+	return lines, conns
+}
+
+func receiveLine(t *testing.T, lines <-chan string) string {
+	t.Helper()
+	select {
+	case line := <-lines:
+		return line
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for a line")
+		return ""
+	}
+}
+
+func TestNetworkLogWriter_TCP(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	lines, conns := acceptLines(t, l)
+
+	writer, err := NewNetworkLogWriter("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatalf("NewNetworkLogWriter failed: %v", err)
+	}
+	defer writer.Close()
+	var _ LogWriter = writer
+
+	select {
+	case <-conns:
+		t.Fatal("Connection should be dialed lazily")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	logger := New(InfoLevel, writer)
+	logger.Info("first")
+	logger.WithField("k", "v").Error("second")
+
+	if got := receiveLine(t, lines); got != "INFO first" {
+		t.Errorf("Unexpected line %q", got)
+	}
+	if got := receiveLine(t, lines); got != "ERROR k=v second" {
+		t.Errorf("Unexpected line %q", got)
+	}
+}
+
+func TestNetworkLogWriter_LengthPrefix(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	defer l.Close()
+
+	writer, err := NewNetworkLogWriterFromConfig(&NetworkConfig{
+		Network: "tcp",
+		Address: l.Addr().String(),
+		Framing: FramingLengthPrefix,
+	})
+	if err != nil {
+		t.Fatalf("NewNetworkLogWriterFromConfig failed: %v", err)
+	}
+	defer writer.Close()
+
+	if _, err := writer.Write([]byte("multi\nline")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	conn, err := l.Accept()
+	if err != nil {
+		t.Fatalf("Accept failed: %v", err)
+	}
+	defer conn.Close()
+
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		t.Fatalf("Reading length failed: %v", err)
+	}
+	message := make([]byte, binary.BigEndian.Uint32(header))
+	if _, err := io.ReadFull(conn, message); err != nil {
+		t.Fatalf("Reading message failed: %v", err)
+	}
+	if string(message) != "multi\nline" {
+		t.Errorf("Unexpected message %q", message)
+	}
+}
+
+func TestNetworkLogWriter_Reconnect(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	lines, conns := acceptLines(t, l)
+
+	writer, err := NewNetworkLogWriter("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatalf("NewNetworkLogWriter failed: %v", err)
+	}
+	defer writer.Close()
+
+	if _, err := writer.Write([]byte("before")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	receiveLine(t, lines)
+
+	// The server drops the connection. Writes may succeed locally until the
+	// reset is noticed, after which the writer reconnects.
+	(<-conns).Close()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		_, _ = writer.Write([]byte("after"))
+		select {
+		case line := <-lines:
+			if line != "after" {
+				t.Errorf("Unexpected line %q", line)
+			}
+			return
+		case <-time.After(20 * time.Millisecond):
+		}
+	}
+	t.Fatal("Expected the writer to reconnect")
+}
+
+func TestNetworkLogWriter_Backoff(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+
+	writer, err := NewNetworkLogWriterFromConfig(&NetworkConfig{
+		Network:    "tcp",
+		Address:    addr,
+		MinBackoff: time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("NewNetworkLogWriterFromConfig failed: %v", err)
+	}
+	defer writer.Close()
+
+	if _, err := writer.Write([]byte("refused")); err == nil || errors.Is(err, ErrNetworkReconnecting) {
+		t.Fatalf("Expected dial error, got %v", err)
+	}
+	if _, err := writer.Write([]byte("skipped")); !errors.Is(err, ErrNetworkReconnecting) {
+		t.Errorf("Expected ErrNetworkReconnecting during backoff, got %v", err)
+	}
+}
+
+func TestNetworkLogWriter_UDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("ListenPacket failed: %v", err)
+	}
+	defer conn.Close()
+
+	writer, err := NewNetworkLogWriter("udp", conn.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("NewNetworkLogWriter failed: %v", err)
+	}
+	defer writer.Close()
+
+	if _, err := writer.Write([]byte("datagram")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	buf := make([]byte, 1024)
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("ReadFrom failed: %v", err)
+	}
+	if string(buf[:n]) != "datagram\n" {
+		t.Errorf("Unexpected datagram %q", buf[:n])
+	}
+}
+
+func TestNetworkLogWriter_Unix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.sock")
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+	lines, _ := acceptLines(t, l)
+
+	writer, err := NewNetworkLogWriter("unix", path)
+	if err != nil {
+		t.Fatalf("NewNetworkLogWriter failed: %v", err)
+	}
+	defer writer.Close()
+
+	if _, err := writer.Write([]byte("local")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if got := receiveLine(t, lines); got != "local" {
+		t.Errorf("Unexpected line %q", got)
+	}
+}
+
+func TestNetworkLogWriter_TLS(t *testing.T) {
+	// httptest provides a certificate valid for 127.0.0.1.
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+
+	l, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: server.TLS.Certificates})
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	lines, _ := acceptLines(t, l)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+
+	writer, err := NewNetworkLogWriterFromConfig(&NetworkConfig{
+		Network: "tcp",
+		Address: l.Addr().String(),
+		TLS:     &tls.Config{RootCAs: roots},
+	})
+	if err != nil {
+		t.Fatalf("NewNetworkLogWriterFromConfig failed: %v", err)
+	}
+	defer writer.Close()
+
+	if _, err := writer.Write([]byte("secure")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if got := receiveLine(t, lines); got != "secure" {
+		t.Errorf("Unexpected line %q", got)
+	}
+}
+
+func TestNetworkLogWriter_InvalidConfig(t *testing.T) {
+	if _, err := NewNetworkLogWriter("icmp", "localhost:1"); err == nil {
+		t.Error("Expected error for unsupported network")
+	}
+	if _, err := NewNetworkLogWriter("tcp", ""); err == nil {
+		t.Error("Expected error without address")
+	}
+	if _, err := NewNetworkLogWriterFromConfig(&NetworkConfig{Network: "udp", Address: "localhost:1", TLS: &tls.Config{}}); err == nil {
+		t.Error("Expected error for TLS over UDP")
+	}
+}
+
+func TestNetworkLogWriter_Close(t *testing.T) {
+	writer, err := NewNetworkLogWriter("tcp", "127.0.0.1:1")
+	if err != nil {
+		t.Fatalf("NewNetworkLogWriter failed: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Errorf("Close without connection failed: %v", err)
+	}
+	if _, err := writer.Write([]byte("late")); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Expected os.ErrClosed, got %v", err)
+	}
+	if err := writer.Close(); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Expected os.ErrClosed on second close, got %v", err)
+	}
+}
@@ -0,0 +1,472 @@
+package balogan
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Default OTLP exporter settings.
+const (
+	// DefaultOTLPEndpoint is the OTLP/HTTP logs endpoint of a local collector.
+	DefaultOTLPEndpoint = "http://localhost:4318/v1/logs"
+	// DefaultOTLPScopeName is the instrumentation scope reported for exported records.
+	DefaultOTLPScopeName = "github.com/dr3dnought/balogan"
+	// DefaultOTLPBatchSize is the maximum number of records per export request.
+	DefaultOTLPBatchSize = 512
+	// DefaultOTLPFlushInterval is how often pending records are exported.
+	DefaultOTLPFlushInterval = time.Second
+	// DefaultOTLPTimeout limits every export request.
+	DefaultOTLPTimeout = 10 * time.Second
+	// DefaultOTLPMaxRetries is the number of retries of a failed export.
+	DefaultOTLPMaxRetries = 3
+	// DefaultOTLPRetryBackoff is the delay before the first retry.
+	DefaultOTLPRetryBackoff = 500 * time.Millisecond
+)
+
+// otelMaxDepth limits how deep nested field values are converted into attributes.
+const otelMaxDepth = 10
+
+// Field keys from which trace context is read when it is not in the record's context.
+const (
+	TraceIDFieldKey = "trace_id"
+	SpanIDFieldKey  = "span_id"
+)
+
+type traceContextKeyType struct{}
+
+var traceContextKey traceContextKeyType
+
+type traceContext struct {
+	traceID string
+	spanID  string
+}
+
+// ContextWithTrace returns a copy of ctx carrying the given hex encoded trace and span IDs.
+// Records of a logger bound to the context with ForContext are exported with these IDs.
+//
+// Parameters:
+//
+//	ctx: The parent context.
+//	traceID: The 32 character hex trace ID.
+//	spanID: The 16 character hex span ID.
+//
+// Example:
+//
+//	ctx = ContextWithTrace(ctx, r.Header.Get("X-Trace-Id"), r.Header.Get("X-Span-Id"))
+//	logger.ForContext(ctx).Info("Handling request")
+func ContextWithTrace(ctx context.Context, traceID, spanID string) context.Context {
+	return context.WithValue(ctx, traceContextKey, traceContext{traceID: traceID, spanID: spanID})
+}
+
+// TraceFromContext returns the trace and span IDs stored by ContextWithTrace.
+func TraceFromContext(ctx context.Context) (traceID, spanID string) {
+	if ctx == nil {
+		return "", ""
+	}
+	tc, _ := ctx.Value(traceContextKey).(traceContext)
+	return tc.traceID, tc.spanID
+}
+
+// TraceExtractor returns the hex encoded trace and span IDs of a context.
+// It allows trace context managed by a tracing library to be exported,
+// for example with the OpenTelemetry SDK:
+//
+//	func(ctx context.Context) (string, string) {
+//		sc := trace.SpanContextFromContext(ctx)
+//		return sc.TraceID().String(), sc.SpanID().String()
+//	}
+type TraceExtractor func(ctx context.Context) (traceID, spanID string)
+
+// OTelAnyValue is an OpenTelemetry AnyValue in OTLP/JSON encoding.
+// Exactly one of the fields is set.
+type OTelAnyValue struct {
+	StringValue *string           `json:"stringValue,omitempty"`
+	BoolValue   *bool             `json:"boolValue,omitempty"`
+	IntValue    *string           `json:"intValue,omitempty"`
+	DoubleValue *float64          `json:"doubleValue,omitempty"`
+	ArrayValue  *OTelArrayValue   `json:"arrayValue,omitempty"`
+	KvlistValue *OTelKeyValueList `json:"kvlistValue,omitempty"`
+	BytesValue  []byte            `json:"bytesValue,omitempty"`
+}
+
+// OTelArrayValue is a list of OpenTelemetry values.
+type OTelArrayValue struct {
+	Values []OTelAnyValue `json:"values"`
+}
+
+// OTelKeyValueList is a list of OpenTelemetry key-value pairs.
+type OTelKeyValueList struct {
+	Values []OTelKeyValue `json:"values"`
+}
+
+// OTelKeyValue is an OpenTelemetry attribute.
+type OTelKeyValue struct {
+	Key   string       `json:"key"`
+	Value OTelAnyValue `json:"value"`
+}
+
+// OTelLogRecord is a record in the OpenTelemetry log data model, in OTLP/JSON encoding.
+type OTelLogRecord struct {
+	TimeUnixNano         string         `json:"timeUnixNano"`
+	ObservedTimeUnixNano string         `json:"observedTimeUnixNano"`
+	SeverityNumber       int            `json:"severityNumber"`
+	SeverityText         string         `json:"severityText"`
+	Body                 OTelAnyValue   `json:"body"`
+	Attributes           []OTelKeyValue `json:"attributes,omitempty"`
+	TraceID              string         `json:"traceId,omitempty"`
+	SpanID               string         `json:"spanId,omitempty"`
+}
+
+// NewOTelLogRecord maps a record to the OpenTelemetry log data model.
+//
+// The level is mapped with LogLevel.OTelSeverity, the message becomes the body
+// and fields become attributes. The logger name and caller are reported as the
+// "logger.name", "code.filepath" and "code.lineno" attributes.
+//
+// Trace and span IDs are read from the record's context with extract, or with
+// TraceFromContext when extract is nil. When the context has none, the
+// TraceIDFieldKey and SpanIDFieldKey fields are used instead. The trace and
+// span IDs are set independently. IDs which are not valid hex of the right
+// length are not used; such fields are kept as attributes.
+func NewOTelLogRecord(record *Record, extract TraceExtractor) OTelLogRecord {
+	if extract == nil {
+		extract = TraceFromContext
+	}
+
+	otelRecord := OTelLogRecord{
+		TimeUnixNano:         strconv.FormatInt(record.Time.UnixNano(), 10),
+		ObservedTimeUnixNano: strconv.FormatInt(time.Now().UnixNano(), 10),
+		SeverityNumber:       record.Level.OTelSeverity(),
+		SeverityText:         record.Level.String(),
+		Body:                 otelValue(record.Message, 0),
+	}
+
+	if record.Context != nil {
+		traceID, spanID := extract(record.Context)
+		if validHexID(traceID, 16) {
+			otelRecord.TraceID = strings.ToLower(traceID)
+		}
+		if validHexID(spanID, 8) {
+			otelRecord.SpanID = strings.ToLower(spanID)
+		}
+	}
+
+	if record.Logger != "" {
+		otelRecord.Attributes = append(otelRecord.Attributes, otelAttribute("logger.name", record.Logger))
+	}
+	if record.Caller != "" {
+		file, line := record.Caller, ""
+		if i := strings.LastIndexByte(file, ':'); i >= 0 {
+			file, line = file[:i], file[i+1:]
+		}
+		otelRecord.Attributes = append(otelRecord.Attributes, otelAttribute("code.filepath", file))
+		if n, err := strconv.Atoi(line); err == nil {
+			otelRecord.Attributes = append(otelRecord.Attributes, otelAttribute("code.lineno", n))
+		}
+	}
+	for _, k := range sortedKeys(record.Fields) {
+		v := record.Fields[k]
+		if k == TraceIDFieldKey && otelRecord.TraceID == "" {
+			if id := fmt.Sprint(v); validHexID(id, 16) {
+				otelRecord.TraceID = strings.ToLower(id)
+				continue
+			}
+		}
+		if k == SpanIDFieldKey && otelRecord.SpanID == "" {
+			if id := fmt.Sprint(v); validHexID(id, 8) {
+				otelRecord.SpanID = strings.ToLower(id)
+				continue
+			}
+		}
+		otelRecord.Attributes = append(otelRecord.Attributes, otelAttribute(k, v))
+	}
+	return otelRecord
+}
+
+// OTLPConfig configures an OTLPExporter.
+type OTLPConfig struct {
+	// Endpoint is the URL of the OTLP/HTTP logs endpoint. DefaultOTLPEndpoint is used when empty.
+	Endpoint string
+	// Headers are added to every request, for example for authentication.
+	Headers map[string]string
+	// Compress gzips request bodies.
+	Compress bool
+
+	// ServiceName is reported as the "service.name" resource attribute.
+	// The executable name is used when empty.
+	ServiceName string
+	// ResourceAttributes are additional resource attributes, such as "deployment.environment".
+	ResourceAttributes Fields
+	// ScopeName is the instrumentation scope name. DefaultOTLPScopeName is used when empty.
+	ScopeName string
+
+	// BatchSize is the maximum number of records per request. DefaultOTLPBatchSize is used when zero.
+	BatchSize int
+	// FlushInterval is how often pending records are exported. DefaultOTLPFlushInterval is used when zero.
+	FlushInterval time.Duration
+	// Timeout limits every request. DefaultOTLPTimeout is used when zero.
+	Timeout time.Duration
+	// MaxRetries is the number of retries of a failed request. DefaultOTLPMaxRetries
+	// is used when zero, a negative value disables retries.
+	MaxRetries int
+	// RetryBackoff is the delay before the first retry, doubled for every further retry
+	// up to 30 seconds. DefaultOTLPRetryBackoff is used when zero.
+	RetryBackoff time.Duration
+
+	// Client sends the requests. http.DefaultClient is used when nil.
+	Client *http.Client
+	// TraceExtractor reads trace context from records. TraceFromContext is used when nil.
+	TraceExtractor TraceExtractor
+	// ErrorHandler receives errors of background exports. When nil, they are
+	// returned by the next WriteRecord call and reach the logger's error handler.
+	ErrorHandler ErrorHandler
+}
+
+// OTLPExporter exports records to an OpenTelemetry collector with OTLP/HTTP in JSON encoding.
+//
+// Records are batched and sent when BatchSize records are pending, every
+// FlushInterval, on Flush and on Close. Failed requests are retried with
+// exponential backoff on network errors, 429 and 5xx responses.
+type OTLPExporter struct {
+	cfg      OTLPConfig
+	resource otlpResource
+	retry    retryPolicy
+	batcher  *batcher[OTelLogRecord]
+}
+
+// NewOTLPExporter creates a new OTLPExporter.
+func NewOTLPExporter(cfg *OTLPConfig) (*OTLPExporter, error) {
+	c := OTLPConfig{}
+	if cfg != nil {
+		c = *cfg
+	}
+
+	if c.Endpoint == "" {
+		c.Endpoint = DefaultOTLPEndpoint
+	}
+	if u, err := url.Parse(c.Endpoint); err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("otlp: invalid endpoint %q", c.Endpoint)
+	}
+	if c.ServiceName == "" {
+		c.ServiceName = filepath.Base(os.Args[0])
+	}
+	if c.ScopeName == "" {
+		c.ScopeName = DefaultOTLPScopeName
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = DefaultOTLPBatchSize
+	}
+	if c.FlushInterval <= 0 {
+		c.FlushInterval = DefaultOTLPFlushInterval
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = DefaultOTLPTimeout
+	}
+	if c.MaxRetries == 0 {
+		c.MaxRetries = DefaultOTLPMaxRetries
+	}
+	if c.RetryBackoff <= 0 {
+		c.RetryBackoff = DefaultOTLPRetryBackoff
+	}
+	if c.Client == nil {
+		c.Client = http.DefaultClient
+	}
+
+	resource := otlpResource{Attributes: []OTelKeyValue{otelAttribute("service.name", c.ServiceName)}}
+	for _, k := range sortedKeys(c.ResourceAttributes) {
+		resource.Attributes = append(resource.Attributes, otelAttribute(k, c.ResourceAttributes[k]))
+	}
+
+	e := &OTLPExporter{
+		cfg:      c,
+		resource: resource,
+		retry:    retryPolicy{maxRetries: c.MaxRetries, backoff: c.RetryBackoff, jitter: true},
+	}
+	e.batcher = newBatcher(c.BatchSize, c.FlushInterval, c.ErrorHandler, e.export)
+	return e, nil
+}
+
+// Write queues bytes as the body of an INFO level record.
+func (e *OTLPExporter) Write(bytes []byte) (int, error) {
+	record := &Record{Time: time.Now(), Level: InfoLevel, Message: string(bytes)}
+	if err := e.WriteRecord(record, bytes); err != nil {
+		return 0, err
+	}
+	return len(bytes), nil
+}
+
+// WriteRecord maps the record with NewOTelLogRecord and queues it for export.
+func (e *OTLPExporter) WriteRecord(record *Record, line []byte) error {
+	return e.batcher.add(NewOTelLogRecord(record, e.cfg.TraceExtractor))
+}
+
+// Flush exports all pending records.
+func (e *OTLPExporter) Flush() error {
+	return e.batcher.flush()
+}
+
+// Close exports all pending records and stops the exporter.
+func (e *OTLPExporter) Close() error {
+	return e.batcher.close()
+}
+
+type otlpResource struct {
+	Attributes []OTelKeyValue `json:"attributes"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpScopeLogs struct {
+	Scope      otlpScope       `json:"scope"`
+	LogRecords []OTelLogRecord `json:"logRecords"`
+}
+
+type otlpResourceLogs struct {
+	Resource  otlpResource    `json:"resource"`
+	ScopeLogs []otlpScopeLogs `json:"scopeLogs"`
+}
+
+type otlpLogsRequest struct {
+	ResourceLogs []otlpResourceLogs `json:"resourceLogs"`
+}
+
+func (e *OTLPExporter) export(records []OTelLogRecord) error {
+	body, err := json.Marshal(otlpLogsRequest{
+		ResourceLogs: []otlpResourceLogs{{
+			Resource: e.resource,
+			ScopeLogs: []otlpScopeLogs{{
+				Scope:      otlpScope{Name: e.cfg.ScopeName},
+				LogRecords: records,
+			}},
+		}},
+	})
+	if err != nil {
+		return fmt.Errorf("otlp: %w", err)
+	}
+
+	headers := map[string]string{"Content-Type": "application/json"}
+	for k, v := range e.cfg.Headers {
+		headers[k] = v
+	}
+	req := httpRequest{method: http.MethodPost, url: e.cfg.Endpoint, headers: headers, body: body, gzip: e.cfg.Compress}
+
+	err = e.retry.do(context.Background(), func() error {
+		_, err := req.send(context.Background(), e.cfg.Client, e.cfg.Timeout)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("otlp: exporting %d records: %w", len(records), err)
+	}
+	return nil
+}
+
+func otelAttribute(key string, value interface{}) OTelKeyValue {
+	return OTelKeyValue{Key: key, Value: otelValue(value, 0)}
+}
+
+// otelValue converts a field value into an OpenTelemetry AnyValue.
+// Maps, slices and structs are converted recursively up to otelMaxDepth,
+// other values are sent as strings. NaN and infinite floats, which OTLP/JSON
+// cannot represent as numbers, are sent as "NaN", "+Inf" and "-Inf".
+func otelValue(value interface{}, depth int) OTelAnyValue {
+	switch v := value.(type) {
+	case nil:
+		return OTelAnyValue{}
+	case string:
+		return OTelAnyValue{StringValue: &v}
+	case bool:
+		return OTelAnyValue{BoolValue: &v}
+	case []byte:
+		return OTelAnyValue{BytesValue: v}
+	case error:
+		s := v.Error()
+		return OTelAnyValue{StringValue: &s}
+	case fmt.Stringer:
+		s := v.String()
+		return OTelAnyValue{StringValue: &s}
+	case time.Time:
+		s := v.Format(time.RFC3339Nano)
+		return OTelAnyValue{StringValue: &s}
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		s := strconv.FormatInt(rv.Int(), 10)
+		return OTelAnyValue{IntValue: &s}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		s := strconv.FormatUint(rv.Uint(), 10)
+		return OTelAnyValue{IntValue: &s}
+	case reflect.Float32, reflect.Float64:
+		f := rv.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			s := strconv.FormatFloat(f, 'g', -1, 64)
+			return OTelAnyValue{StringValue: &s}
+		}
+		return OTelAnyValue{DoubleValue: &f}
+	case reflect.String:
+		s := rv.String()
+		return OTelAnyValue{StringValue: &s}
+	}
+
+	if depth < otelMaxDepth {
+		switch rv.Kind() {
+		case reflect.Pointer, reflect.Interface:
+			if rv.IsNil() {
+				return OTelAnyValue{}
+			}
+			return otelValue(rv.Elem().Interface(), depth+1)
+		case reflect.Slice, reflect.Array:
+			values := make([]OTelAnyValue, rv.Len())
+			for i := range rv.Len() {
+				values[i] = otelValue(rv.Index(i).Interface(), depth+1)
+			}
+			return OTelAnyValue{ArrayValue: &OTelArrayValue{Values: values}}
+		case reflect.Map:
+			if rv.Type().Key().Kind() == reflect.String {
+				values := make([]OTelKeyValue, 0, rv.Len())
+				iter := rv.MapRange()
+				for iter.Next() {
+					values = append(values, OTelKeyValue{Key: iter.Key().String(), Value: otelValue(iter.Value().Interface(), depth+1)})
+				}
+				return OTelAnyValue{KvlistValue: &OTelKeyValueList{Values: values}}
+			}
+		}
+	}
+
+	// Structs and other values are sent as their JSON, or fmt representation.
+	var s string
+	if data, err := json.Marshal(value); err == nil {
+		s = string(data)
+	} else {
+		s = fmt.Sprint(value)
+	}
+	return OTelAnyValue{StringValue: &s}
+}
+
+// validHexID reports whether id is a hex encoded, non-zero ID of n bytes.
+func validHexID(id string, n int) bool {
+	b, err := hex.DecodeString(id)
+	if err != nil || len(b) != n {
+		return false
+	}
+	for _, c := range b {
+		if c != 0 {
+			return true
+		}
+	}
+	return false
+}
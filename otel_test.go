@@ -0,0 +1,336 @@
+package balogan
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"testing"
+	"time"
+)
+
+const (
+	testTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	testSpanID  = "00f067aa0ba902b7"
+)
+
+func decodeOTLP(body []byte) (otlpLogsRequest, error) {
+	var req otlpLogsRequest
+	err := json.Unmarshal(body, &req)
+	return req, err
+}
+
+// otlpRecords returns the log records of the requests the collector accepted.
+func otlpRecords(collector *recordingServer[otlpLogsRequest]) []OTelLogRecord {
+	var records []OTelLogRecord
+	for _, req := range collector.accepted() {
+		for _, rl := range req.ResourceLogs {
+			for _, sl := range rl.ScopeLogs {
+				records = append(records, sl.LogRecords...)
+			}
+		}
+	}
+	return records
+}
+
+func findAttribute(attributes []OTelKeyValue, key string) (OTelAnyValue, bool) {
+	for _, kv := range attributes {
+		if kv.Key == key {
+			return kv.Value, true
+		}
+	}
+	return OTelAnyValue{}, false
+}
+
+func TestNewOTelLogRecord(t *testing.T) {
+	record := &Record{
+		Time:    time.Unix(1700000000, 5),
+		Level:   WarningLevel,
+		Message: "disk almost full",
+		Fields:  Fields{"disk": "/dev/sda", "usage": 95, "ratio": 0.95, "ok": false, "tags": []string{"a", "b"}},
+		Logger:  "storage",
+		Caller:  "app/disk.go:42",
+	}
+
+	otelRecord := NewOTelLogRecord(record, nil)
+
+	if otelRecord.TimeUnixNano != "1700000000000000005" {
+		t.Errorf("Unexpected time %q", otelRecord.TimeUnixNano)
+	}
+	if otelRecord.SeverityNumber != 13 || otelRecord.SeverityText != "WARNING" {
+		t.Errorf("Unexpected severity %d %q", otelRecord.SeverityNumber, otelRecord.SeverityText)
+	}
+	if otelRecord.Body.StringValue == nil || *otelRecord.Body.StringValue != "disk almost full" {
+		t.Errorf("Unexpected body %+v", otelRecord.Body)
+	}
+
+	if v, _ := findAttribute(otelRecord.Attributes, "usage"); v.IntValue == nil || *v.IntValue != "95" {
+		t.Errorf("Expected int attribute, got %+v", v)
+	}
+	if v, _ := findAttribute(otelRecord.Attributes, "ratio"); v.DoubleValue == nil || *v.DoubleValue != 0.95 {
+		t.Errorf("Expected double attribute, got %+v", v)
+	}
+	if v, _ := findAttribute(otelRecord.Attributes, "ok"); v.BoolValue == nil || *v.BoolValue {
+		t.Errorf("Expected bool attribute, got %+v", v)
+	}
+	if v, _ := findAttribute(otelRecord.Attributes, "tags"); v.ArrayValue == nil || len(v.ArrayValue.Values) != 2 {
+		t.Errorf("Expected array attribute, got %+v", v)
+	}
+	if v, _ := findAttribute(otelRecord.Attributes, "logger.name"); v.StringValue == nil || *v.StringValue != "storage" {
+		t.Errorf("Expected logger name attribute, got %+v", v)
+	}
+	if v, _ := findAttribute(otelRecord.Attributes, "code.lineno"); v.IntValue == nil || *v.IntValue != "42" {
+		t.Errorf("Expected caller line attribute, got %+v", v)
+	}
+	if otelRecord.TraceID != "" || otelRecord.SpanID != "" {
+		t.Errorf("Expected no trace context, got %q %q", otelRecord.TraceID, otelRecord.SpanID)
+	}
+}
+
+func TestNewOTelLogRecord_TraceContext(t *testing.T) {
+	ctx := ContextWithTrace(context.Background(), testTraceID, testSpanID)
+	otelRecord := NewOTelLogRecord(&Record{Level: InfoLevel, Context: ctx}, nil)
+	if otelRecord.TraceID != testTraceID || otelRecord.SpanID != testSpanID {
+		t.Errorf("Expected trace context from context, got %q %q", otelRecord.TraceID, otelRecord.SpanID)
+	}
+
+	fields := Fields{TraceIDFieldKey: testTraceID, SpanIDFieldKey: testSpanID}
+	otelRecord = NewOTelLogRecord(&Record{Level: InfoLevel, Fields: fields}, nil)
+	if otelRecord.TraceID != testTraceID || otelRecord.SpanID != testSpanID {
+		t.Errorf("Expected trace context from fields, got %q %q", otelRecord.TraceID, otelRecord.SpanID)
+	}
+	if len(otelRecord.Attributes) != 0 {
+		t.Errorf("Trace fields should not be attributes, got %+v", otelRecord.Attributes)
+	}
+
+	extract := func(context.Context) (string, string) { return "not-hex", testSpanID }
+	otelRecord = NewOTelLogRecord(&Record{Level: InfoLevel, Context: context.Background()}, extract)
+	if otelRecord.TraceID != "" || otelRecord.SpanID != testSpanID {
+		t.Errorf("Invalid trace ID should be dropped and the span ID kept, got %q %q", otelRecord.TraceID, otelRecord.SpanID)
+	}
+
+	// A trace ID without span ID is kept, invalid IDs stay attributes.
+	uuid := "4bf92f35-77b3-4da6-a3ce-929d0e0e4736"
+	fields = Fields{TraceIDFieldKey: testTraceID, SpanIDFieldKey: uuid}
+	otelRecord = NewOTelLogRecord(&Record{Level: InfoLevel, Fields: fields}, nil)
+	if otelRecord.TraceID != testTraceID || otelRecord.SpanID != "" {
+		t.Errorf("Expected only the valid trace ID, got %q %q", otelRecord.TraceID, otelRecord.SpanID)
+	}
+	if v, ok := findAttribute(otelRecord.Attributes, SpanIDFieldKey); !ok || v.StringValue == nil || *v.StringValue != uuid {
+		t.Errorf("Invalid span ID should be kept as attribute, got %+v", otelRecord.Attributes)
+	}
+
+	fields = Fields{TraceIDFieldKey: uuid}
+	otelRecord = NewOTelLogRecord(&Record{Level: InfoLevel, Fields: fields}, nil)
+	if v, ok := findAttribute(otelRecord.Attributes, TraceIDFieldKey); otelRecord.TraceID != "" || !ok || *v.StringValue != uuid {
+		t.Errorf("Invalid trace ID should be kept as attribute, got %q %+v", otelRecord.TraceID, otelRecord.Attributes)
+	}
+}
+
+func TestOTLPExporter_Export(t *testing.T) {
+	collector := newRecordingServer(t, decodeOTLP)
+	exporter, err := NewOTLPExporter(&OTLPConfig{
+		Endpoint:           collector.URL + "/v1/logs",
+		Headers:            map[string]string{"Authorization": "Bearer secret"},
+		Compress:           true,
+		ServiceName:        "checkout",
+		ResourceAttributes: Fields{"deployment.environment": "prod"},
+		FlushInterval:      time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("NewOTLPExporter failed: %v", err)
+	}
+
+	ctx := ContextWithTrace(context.Background(), testTraceID, testSpanID)
+	logger := New(InfoLevel, exporter).ForContext(ctx).WithField("order", 7)
+	logger.Info("order placed")
+	logger.Error("payment failed")
+
+	if err := exporter.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	requests := collector.accepted()
+	if len(requests) != 1 {
+		t.Fatalf("Expected 1 export request, got %d", len(requests))
+	}
+	if got := collector.captured()[0].header.Get("Authorization"); got != "Bearer secret" {
+		t.Errorf("Expected custom header, got %q", got)
+	}
+
+	resource := requests[0].ResourceLogs[0].Resource
+	if v, _ := findAttribute(resource.Attributes, "service.name"); v.StringValue == nil || *v.StringValue != "checkout" {
+		t.Errorf("Expected service.name resource attribute, got %+v", resource.Attributes)
+	}
+	if _, ok := findAttribute(resource.Attributes, "deployment.environment"); !ok {
+		t.Errorf("Expected custom resource attribute, got %+v", resource.Attributes)
+	}
+	if scope := requests[0].ResourceLogs[0].ScopeLogs[0].Scope.Name; scope != DefaultOTLPScopeName {
+		t.Errorf("Expected default scope name, got %q", scope)
+	}
+
+	records := otlpRecords(collector)
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(records))
+	}
+	if records[1].SeverityNumber != 17 || *records[1].Body.StringValue != "payment failed" {
+		t.Errorf("Unexpected record %+v", records[1])
+	}
+	if records[0].TraceID != testTraceID {
+		t.Errorf("Expected trace ID from the logger's context, got %q", records[0].TraceID)
+	}
+}
+
+func TestOTLPExporter_NonFiniteFloats(t *testing.T) {
+	collector := newRecordingServer(t, decodeOTLP)
+	exporter, err := NewOTLPExporter(&OTLPConfig{Endpoint: collector.URL, FlushInterval: time.Hour})
+	if err != nil {
+		t.Fatalf("NewOTLPExporter failed: %v", err)
+	}
+
+	logger := New(InfoLevel, exporter)
+	logger.Info("normal record")
+	logger.WithFields(Fields{"ratio": math.NaN(), "max": math.Inf(1), "min": math.Inf(-1)}).Info("non-finite record")
+
+	if err := exporter.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	records := otlpRecords(collector)
+	if len(records) != 2 {
+		t.Fatalf("Expected both records to be exported, got %d", len(records))
+	}
+	for key, expected := range map[string]string{"ratio": "NaN", "max": "+Inf", "min": "-Inf"} {
+		if v, _ := findAttribute(records[1].Attributes, key); v.StringValue == nil || *v.StringValue != expected {
+			t.Errorf("Expected %s to be exported as %q, got %+v", key, expected, v)
+		}
+	}
+}
+
+func TestOTLPExporter_BatchSize(t *testing.T) {
+	collector := newRecordingServer(t, decodeOTLP)
+	exporter, err := NewOTLPExporter(&OTLPConfig{
+		Endpoint:      collector.URL,
+		BatchSize:     2,
+		FlushInterval: time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("NewOTLPExporter failed: %v", err)
+	}
+	defer exporter.Close()
+
+	logger := New(InfoLevel, exporter)
+	logger.Info("one")
+	logger.Info("two")
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(otlpRecords(collector)) < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := len(otlpRecords(collector)); got != 2 {
+		t.Errorf("Expected a full batch to be exported without Flush, got %d records", got)
+	}
+}
+
+func TestOTLPExporter_Retry(t *testing.T) {
+	collector := newRecordingServer(t, decodeOTLP)
+	collector.failures = 2
+	collector.status = http.StatusServiceUnavailable
+
+	exporter, err := NewOTLPExporter(&OTLPConfig{
+		Endpoint:      collector.URL,
+		FlushInterval: time.Hour,
+		RetryBackoff:  time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("NewOTLPExporter failed: %v", err)
+	}
+	defer exporter.Close()
+
+	New(InfoLevel, exporter).Info("retried")
+	if err := exporter.Flush(); err != nil {
+		t.Fatalf("Flush should succeed after retries, got %v", err)
+	}
+	if len(otlpRecords(collector)) != 1 {
+		t.Errorf("Expected the record to be exported after retries")
+	}
+}
+
+func TestOTLPExporter_PermanentError(t *testing.T) {
+	collector := newRecordingServer(t, decodeOTLP)
+	collector.failures = 1
+	collector.status = http.StatusBadRequest
+
+	exporter, err := NewOTLPExporter(&OTLPConfig{
+		Endpoint:      collector.URL,
+		FlushInterval: time.Hour,
+		RetryBackoff:  time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("NewOTLPExporter failed: %v", err)
+	}
+	defer exporter.Close()
+
+	New(InfoLevel, exporter).Info("rejected")
+	err = exporter.Flush()
+
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Expected HTTP 400 error, got %v", err)
+	}
+	if collector.remainingFailures() != 0 || len(otlpRecords(collector)) != 0 {
+		t.Error("Client errors should not be retried")
+	}
+}
+
+func TestOTLPExporter_BackgroundErrors(t *testing.T) {
+	collector := newRecordingServer(t, decodeOTLP)
+	collector.failures = 1
+	collector.status = http.StatusBadRequest
+
+	handled := make(chan error, 1)
+	exporter, err := NewOTLPExporter(&OTLPConfig{
+		Endpoint:      collector.URL,
+		FlushInterval: 10 * time.Millisecond,
+		ErrorHandler:  ErrorHandlerFunc(func(err error) { handled <- err }),
+	})
+	if err != nil {
+		t.Fatalf("NewOTLPExporter failed: %v", err)
+	}
+	defer exporter.Close()
+
+	New(InfoLevel, exporter).Info("rejected")
+
+	select {
+	case err := <-handled:
+		var httpErr *HTTPError
+		if !errors.As(err, &httpErr) {
+			t.Errorf("Expected HTTPError, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected background export error to be handled")
+	}
+}
+
+func TestOTLPExporter_InvalidEndpoint(t *testing.T) {
+	if _, err := NewOTLPExporter(&OTLPConfig{Endpoint: "localhost:4318"}); err == nil {
+		t.Error("Expected error for endpoint without scheme")
+	}
+}
+
+func TestOTLPExporter_Closed(t *testing.T) {
+	exporter, err := NewOTLPExporter(&OTLPConfig{Endpoint: "http://127.0.0.1:1"})
+	if err != nil {
+		t.Fatalf("NewOTLPExporter failed: %v", err)
+	}
+	if err := exporter.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if _, err := exporter.Write([]byte("late")); err == nil {
+		t.Error("Expected error writing to a closed exporter")
+	}
+	if err := exporter.Close(); err == nil {
+		t.Error("Expected error closing twice")
+	}
+}
@@ -0,0 +1,187 @@
+package balogan
+
+// Processor represents a function which changes a record before it is formatted and written.
+// Processors run in registration order after the level and conditions have been checked.
+// Returning false drops the record; later processors, hooks and writers do not see it.
+//
+// Unlike conditions, processors may modify the record: add or rename fields,
+// change the level or rewrite the message. If a processor lowers the level below
+// the logger's minimum level, the record is dropped.
+//
+// Example usage:
+//
+//	processor := func(record *Record) bool {
+//		record.Fields["hostname"] = hostname
+//		return true
+//	}
+//	logger.WithProcessor(processor).Info("Record with hostname")
+type Processor func(record *Record) bool
+
+// WithProcessor returns a new Logger instance which passes every record through the processor.
+// Processors are inherited by loggers derived from the returned one, like conditions added with When.
+//
+// Parameters:
+//
+//	processor: The processor to append to the chain.
+//
+// Example:
+//
+//	logger.WithProcessor(RenameField("msg_id", "message_id")).Info("Renamed field")
+func (l *Logger) WithProcessor(processor Processor) *Logger {
+	processors := make([]Processor, len(l.processors))
+	copy(processors, l.processors)
+	processors = append(processors, processor)
+
+	return &Logger{
+		level:             l.level,
+		writers:           l.writers,
+		prefixes:          l.prefixes,
+		errorHandler:      l.errorHandler,
+		concurrency:       l.concurrency,
+		fields:            l.fields.Copy(),
+		fieldsFormatter:   l.fieldsFormatter,
+		conditions:        l.conditions,
+		levelConditions:   l.levelConditions,
+		contextConditions: l.contextConditions,
+		exitFunc:          l.exitFunc,
+		exitCode:          l.exitCode,
+		exitTimeout:       l.exitTimeout,
+		exitHandlers:      l.exitHandlers,
+		repanic:           l.repanic,
+		beforeHooks:       l.beforeHooks,
+		afterHooks:        l.afterHooks,
+		processors:        processors,
+		redactor:          l.redactor,
+		encoder:           l.encoder,
+		name:              l.name,
+		reportCaller:      l.reportCaller,
+		ctx:               l.ctx,
+		multiline:         l.multiline,
+	}
+}
+
+// process runs the processor chain over the record.
+// It reports whether the record should still be written.
+func (l *Logger) process(record *Record) bool {
+	for _, processor := range l.processors {
+		if !processor(record) {
+			return false
+		}
+	}
+
+	return record.Level.IsEnabled(l.level)
+}
+
+// AddField returns a processor that sets a field on every record.
+// An existing field with the same key is overwritten.
+//
+// Parameters:
+//
+//	key: The field key.
+//	value: The field value.
+//
+// Example:
+//
+//	logger.WithProcessor(AddField("version", "1.2.3")).Info("Started")
+func AddField(key string, value interface{}) Processor {
+	return func(record *Record) bool {
+		if record.Fields == nil {
+			record.Fields = make(Fields)
+		}
+		record.Fields[key] = value
+		return true
+	}
+}
+
+// RenameField returns a processor that renames a field key.
+// Records without the field are left untouched.
+//
+// Parameters:
+//
+//	from: The current field key.
+//	to: The new field key.
+//
+// Example:
+//
+//	logger.WithProcessor(RenameField("uid", "user_id")).WithField("uid", 42).Info("Login")
+func RenameField(from, to string) Processor {
+	return func(record *Record) bool {
+		value, exists := record.Fields[from]
+		if !exists {
+			return true
+		}
+		delete(record.Fields, from)
+		record.Fields[to] = value
+		return true
+	}
+}
+
+// RemoveFields returns a processor that deletes the given field keys from every record.
+//
+// Parameters:
+//
+//	keys: The field keys to remove.
+//
+// Example:
+//
+//	logger.WithProcessor(RemoveFields("debug_dump")).Info("Without dump")
+func RemoveFields(keys ...string) Processor {
+	return func(record *Record) bool {
+		for _, key := range keys {
+			delete(record.Fields, key)
+		}
+		return true
+	}
+}
+
+// MapLevel returns a processor that changes records of one level to another level.
+//
+// Parameters:
+//
+//	from: The level to change.
+//	to: The new level.
+//
+// Example:
+//
+//	// Downgrade noisy third-party errors to warnings
+//	logger.WithProcessor(MapLevel(ErrorLevel, WarningLevel)).Error("Retrying")
+func MapLevel(from, to LogLevel) Processor {
+	return func(record *Record) bool {
+		if record.Level == from {
+			record.Level = to
+		}
+		return true
+	}
+}
+
+// RewriteMessage returns a processor that replaces the message with the result of rewrite.
+//
+// Parameters:
+//
+//	rewrite: The function receiving the current message and returning the new one.
+//
+// Example:
+//
+//	logger.WithProcessor(RewriteMessage(strings.ToUpper)).Info("loud")
+func RewriteMessage(rewrite func(message string) string) Processor {
+	return func(record *Record) bool {
+		record.Message = rewrite(record.Message)
+		return true
+	}
+}
+
+// DropWhen returns a processor that drops records matching the level condition.
+// It evaluates the condition against the record as changed by earlier processors.
+//
+// Parameters:
+//
+//	condition: The level condition selecting records to drop.
+//
+// Example:
+//
+//	logger.WithProcessor(DropWhen(FieldEquals("path", "/healthz"))).Info("Request")
+func DropWhen(condition LevelCondition) Processor {
+	return func(record *Record) bool {
+		return !condition(record.Level, record.Fields)
+	}
+}
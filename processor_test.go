@@ -0,0 +1,102 @@
+package balogan
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLogger_WithProcessorInheritance(t *testing.T) {
+	parent := New(InfoLevel, &MockWriter{})
+	child := parent.WithProcessor(AddField("a", 1))
+	grandchild := child.WithField("k", "v").WithProcessor(AddField("b", 2))
+
+	if len(parent.processors) != 0 {
+		t.Errorf("Parent should have no processors, got %d", len(parent.processors))
+	}
+	if len(child.processors) != 1 {
+		t.Errorf("Child should have 1 processor, got %d", len(child.processors))
+	}
+	if len(grandchild.processors) != 2 {
+		t.Errorf("Grandchild should have 2 processors, got %d", len(grandchild.processors))
+	}
+}
+
+func TestLogger_ProcessorChain(t *testing.T) {
+	mockWriter := &MockWriter{}
+	logger := New(InfoLevel, mockWriter).
+		WithField("uid", 42).
+		WithField("secret", "x").
+		WithProcessor(AddField("version", "1.0")).
+		WithProcessor(RenameField("uid", "user_id")).
+		WithProcessor(RemoveFields("secret")).
+		WithProcessor(RewriteMessage(strings.ToUpper))
+
+	logger.Info("user logged in")
+
+	expected := "INFO user_id=42 version=1.0 USER LOGGED IN"
+	if got := mockWriter.String(); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+	if _, exists := logger.fields["user_id"]; exists {
+		t.Error("Processors should not modify the logger's own fields")
+	}
+}
+
+func TestLogger_ProcessorMapLevel(t *testing.T) {
+	mockWriter := &MockWriter{}
+	logger := New(InfoLevel, mockWriter).WithProcessor(MapLevel(ErrorLevel, WarningLevel))
+
+	logger.Error("downgraded")
+	if got := mockWriter.String(); got != "WARNING downgraded" {
+		t.Errorf("Expected level to be changed, got %q", got)
+	}
+
+	mockWriter.Reset()
+	logger = New(InfoLevel, mockWriter).WithProcessor(MapLevel(WarningLevel, DebugLevel))
+	logger.Warning("below minimum")
+	if mockWriter.Len() != 0 {
+		t.Errorf("Record lowered below minimum level should be dropped, got %q", mockWriter.String())
+	}
+}
+
+func TestLogger_ProcessorDrop(t *testing.T) {
+	mockWriter := &MockWriter{}
+	hookCalled := false
+	logger := New(InfoLevel, mockWriter).
+		WithProcessor(DropWhen(FieldEquals("path", "/healthz"))).
+		WithHook(BeforeWrite, NewHook(func(*Record) error {
+			hookCalled = true
+			return nil
+		}))
+
+	logger.WithField("path", "/healthz").Info("request")
+	if mockWriter.Len() != 0 {
+		t.Errorf("Dropped record should not be written, got %q", mockWriter.String())
+	}
+	if hookCalled {
+		t.Error("Hooks should not see dropped records")
+	}
+
+	logger.WithField("path", "/users").Info("request")
+	if !strings.Contains(mockWriter.String(), "path=/users request") {
+		t.Errorf("Expected record to be written, got %q", mockWriter.String())
+	}
+}
+
+func TestLogger_ProcessorRunsAfterShouldLog(t *testing.T) {
+	called := false
+	logger := New(WarningLevel, &MockWriter{}).WithProcessor(func(*Record) bool {
+		called = true
+		return true
+	})
+
+	logger.Info("filtered by level")
+	if called {
+		t.Error("Processors should not run for records rejected by shouldLog")
+	}
+
+	logger.When(Never()).Error("filtered by condition")
+	if called {
+		t.Error("Processors should not run for records rejected by conditions")
+	}
+}
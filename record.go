@@ -0,0 +1,84 @@
+package balogan
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Record is a single log entry as it travels through the logger.
+// It is created after the level and conditions have been checked
+// and is passed to hooks before and after writers run.
+//
+// Fields is a copy of the logger's fields, so hooks may modify it
+// without affecting the logger it came from.
+type Record struct {
+	// Time is the moment the record was created.
+	Time time.Time
+	// Level is the log level of the record.
+	Level LogLevel
+	// Message is the formatted log message without prefixes and fields.
+	Message string
+	// Fields holds the structured fields of the record.
+	Fields Fields
+	// Prefixes holds the rendered output of the logger's prefix builders.
+	Prefixes []string
+	// Logger is the name of the logger, empty unless set with WithName.
+	Logger string
+	// Caller is the "dir/file.go:line" location of the logging call,
+	// empty unless caller reporting is enabled with WithCaller.
+	Caller string
+	// Context is the context bound to the logger with ForContext, or nil.
+	Context context.Context
+}
+
+// cloneRecord copies a record together with its fields and prefixes.
+// Writers which use a record after WriteRecord returns, for example on a
+// background goroutine, keep a clone, as the record is shared and hooks may
+// modify it once the writers are done.
+func cloneRecord(record *Record) *Record {
+	clone := *record
+	clone.Fields = record.Fields.Copy()
+	clone.Prefixes = slices.Clone(record.Prefixes)
+	return &clone
+}
+
+// loggerMethodPrefix is the function name prefix shared by all *Logger methods.
+// Frames with this prefix are skipped when looking for the caller, as are
+// frames of the io.Writer returned by Logger.Writer.
+var (
+	loggerMethodPrefix     = reflect.TypeOf(Logger{}).PkgPath() + ".(*Logger)."
+	loggerLineWriterPrefix = reflect.TypeOf(Logger{}).PkgPath() + ".(*logLineWriter)."
+)
+
+// callerLocation returns the location of the first frame outside of the logger's methods.
+func callerLocation() string {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, loggerMethodPrefix) && !strings.HasPrefix(frame.Function, loggerLineWriterPrefix) {
+			return shortCallerPath(frame.File) + ":" + strconv.Itoa(frame.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+// shortCallerPath trims a file path to its last directory and file name.
+func shortCallerPath(file string) string {
+	dir, name := filepath.Split(file)
+	if dir == "" {
+		return name
+	}
+
+	return filepath.Base(dir) + "/" + name
+}
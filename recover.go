@@ -0,0 +1,97 @@
+package balogan
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// Field keys used by Recover for the recovered value and the stack trace.
+const (
+	PanicFieldKey = "panic"
+	StackFieldKey = "stack"
+)
+
+// Recover catches a panic in the current goroutine and logs it at the PANIC level
+// with the recovered value, the stack trace and the logger's fields.
+// After logging the panic is swallowed, unless the logger is configured
+// with WithRepanic(true), in which case the recovered value is panicked again.
+//
+// Recover must be called directly with defer, otherwise it cannot catch the panic.
+//
+// Example:
+//
+//	func handle() {
+//		defer logger.Recover()
+//		// ...
+//	}
+func (l *Logger) Recover() {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	l.logPanic(r, debug.Stack())
+
+	if l.repanic {
+		panic(r)
+	}
+}
+
+// Go runs fn in a new goroutine guarded by Recover.
+// A panic in fn is logged instead of crashing the program silently.
+//
+// Parameters:
+//
+//	fn: The function to run.
+//
+// Example:
+//
+//	logger.WithField("worker", id).Go(worker.Run)
+func (l *Logger) Go(fn func()) {
+	go func() {
+		defer l.Recover()
+		fn()
+	}()
+}
+
+// WithRepanic returns a new Logger instance which re-panics in Recover
+// after the panic has been logged.
+//
+// Parameters:
+//
+//	enabled: Whether recovered panics should be re-panicked.
+func (l *Logger) WithRepanic(enabled bool) *Logger {
+	return &Logger{
+		level:             l.level,
+		writers:           l.writers,
+		prefixes:          l.prefixes,
+		errorHandler:      l.errorHandler,
+		concurrency:       l.concurrency,
+		fields:            l.fields.Copy(),
+		fieldsFormatter:   l.fieldsFormatter,
+		conditions:        l.conditions,
+		levelConditions:   l.levelConditions,
+		contextConditions: l.contextConditions,
+		exitFunc:          l.exitFunc,
+		exitCode:          l.exitCode,
+		exitTimeout:       l.exitTimeout,
+		exitHandlers:      l.exitHandlers,
+		repanic:           enabled,
+		beforeHooks:       l.beforeHooks,
+		afterHooks:        l.afterHooks,
+		processors:        l.processors,
+		redactor:          l.redactor,
+		encoder:           l.encoder,
+		name:              l.name,
+		reportCaller:      l.reportCaller,
+		ctx:               l.ctx,
+		multiline:         l.multiline,
+	}
+}
+
+func (l *Logger) logPanic(value interface{}, stack []byte) {
+	l.WithFields(Fields{
+		PanicFieldKey: value,
+		StackFieldKey: string(stack),
+	}).Log(PanicLevel, fmt.Sprintf("recovered from panic: %v", value))
+}
@@ -0,0 +1,104 @@
+package balogan
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogger_RecoverSwallowsPanic(t *testing.T) {
+	mockWriter := &MockWriter{}
+	logger := New(InfoLevel, mockWriter).WithField("worker", 7)
+
+	func() {
+		defer logger.Recover()
+		panic("something broke")
+	}()
+
+	output := mockWriter.String()
+	if !strings.HasPrefix(output, "PANIC") {
+		t.Errorf("Expected PANIC level, got %q", output)
+	}
+	if !strings.Contains(output, "recovered from panic: something broke") {
+		t.Errorf("Expected recovered value in message, got %q", output)
+	}
+	if !strings.Contains(output, "worker=7") {
+		t.Errorf("Expected logger fields in output, got %q", output)
+	}
+	if !strings.Contains(output, "stack=") || !strings.Contains(output, "TestLogger_RecoverSwallowsPanic") {
+		t.Errorf("Expected stack trace in output, got %q", output)
+	}
+}
+
+func TestLogger_RecoverRepanic(t *testing.T) {
+	mockWriter := &MockWriter{}
+	logger := New(InfoLevel, mockWriter).WithRepanic(true)
+
+	defer func() {
+		r := recover()
+		if r != "again" {
+			t.Errorf("Expected re-panic with original value, got %v", r)
+		}
+		if !strings.Contains(mockWriter.String(), "recovered from panic: again") {
+			t.Errorf("Panic should be logged before re-panic, got %q", mockWriter.String())
+		}
+	}()
+
+	func() {
+		defer logger.Recover()
+		panic("again")
+	}()
+
+	t.Error("Expected re-panic")
+}
+
+func TestLogger_RecoverNoPanic(t *testing.T) {
+	mockWriter := &MockWriter{}
+	logger := New(InfoLevel, mockWriter)
+
+	func() {
+		defer logger.Recover()
+	}()
+
+	if mockWriter.Len() != 0 {
+		t.Errorf("Nothing should be logged without panic, got %q", mockWriter.String())
+	}
+}
+
+type chanWriter struct {
+	lines chan string
+}
+
+func (w *chanWriter) Write(p []byte) (int, error) {
+	w.lines <- string(p)
+	return len(p), nil
+}
+
+func (w *chanWriter) Close() error {
+	return nil
+}
+
+func TestLogger_Go(t *testing.T) {
+	writer := &chanWriter{lines: make(chan string, 1)}
+	logger := New(InfoLevel, writer)
+
+	logger.Go(func() {
+		panic("worker crashed")
+	})
+
+	select {
+	case line := <-writer.lines:
+		if !strings.Contains(line, "recovered from panic: worker crashed") {
+			t.Errorf("Expected recovered panic to be logged, got %q", line)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Panic in goroutine was not logged")
+	}
+}
+
+func TestNewFromConfig_Repanic(t *testing.T) {
+	logger := NewFromConfig(&BaloganConfig{Level: InfoLevel, Repanic: true})
+	if !logger.repanic {
+		t.Error("Repanic not set from config")
+	}
+}
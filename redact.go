@@ -0,0 +1,359 @@
+package balogan
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// DefaultRedactionMask replaces redacted values when Redactor.Mask is empty.
+const DefaultRedactionMask = "[REDACTED]"
+
+// DefaultRedactionMaxDepth limits how deep a Redactor walks nested values
+// when Redactor.MaxDepth is not set.
+const DefaultRedactionMaxDepth = 10
+
+// Detector finds sensitive data inside free text.
+// Every match of Pattern is replaced with the mask unless Validate rejects it.
+type Detector struct {
+	// Pattern matches candidate sensitive substrings.
+	Pattern *regexp.Regexp
+	// Validate optionally confirms a match before it is masked.
+	Validate func(match string) bool
+}
+
+// Predefined detectors for common kinds of sensitive data.
+var (
+	// EmailDetector finds e-mail addresses.
+	EmailDetector = Detector{
+		Pattern: regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`),
+	}
+
+	// CardNumberDetector finds payment card numbers (13 to 19 digits,
+	// optionally separated by spaces or dashes) which pass the Luhn check.
+	CardNumberDetector = Detector{
+		Pattern:  regexp.MustCompile(`\b\d(?:[ \-]?\d){12,18}\b`),
+		Validate: luhnValid,
+	}
+
+	// BearerTokenDetector finds bearer tokens as used in Authorization headers.
+	BearerTokenDetector = Detector{
+		Pattern: regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9\-._~+/]+=*`),
+	}
+)
+
+// DefaultRedactionKeys contains key patterns for commonly sensitive fields.
+var DefaultRedactionKeys = []string{
+	"password",
+	"passwd",
+	"secret",
+	"*token*",
+	"authorization",
+	"cookie",
+	"*api_key*",
+	"*apikey*",
+}
+
+// Redactor masks sensitive data in records before they are formatted.
+// It masks field values whose keys match one of the Keys patterns, scrubs
+// strings with Detectors, and walks nested maps, slices and structs.
+//
+// Key patterns use path.Match syntax (for example "*token*") and are matched
+// case-insensitively against field keys at any nesting level.
+// Struct fields are matched by their json tag name when present.
+//
+// Because redaction happens on the record, the output of every
+// FieldsFormatter is redacted the same way.
+type Redactor struct {
+	// Keys holds patterns of field keys whose values are masked.
+	Keys []string
+	// Detectors scrub sensitive substrings from messages and string values.
+	Detectors []Detector
+	// Mask replaces redacted data. DefaultRedactionMask is used when empty.
+	Mask string
+	// MaxDepth limits how deep nested values are walked.
+	// DefaultRedactionMaxDepth is used when zero.
+	MaxDepth int
+}
+
+// NewDefaultRedactor creates a Redactor with DefaultRedactionKeys and all predefined detectors.
+func NewDefaultRedactor() *Redactor {
+	keys := make([]string, len(DefaultRedactionKeys))
+	copy(keys, DefaultRedactionKeys)
+
+	return &Redactor{
+		Keys:      keys,
+		Detectors: []Detector{EmailDetector, CardNumberDetector, BearerTokenDetector},
+	}
+}
+
+// WithRedactor returns a new Logger instance which redacts every record with the given Redactor.
+// Redaction runs after processors, so fields they add are redacted too, and before hooks and writers.
+//
+// Parameters:
+//
+//	redactor: The Redactor to apply. nil disables redaction.
+//
+// Example:
+//
+//	logger.WithRedactor(NewDefaultRedactor()).
+//		WithField("password", "hunter2").
+//		Info("Login from john@example.com")
+//	// Output: INFO password=[REDACTED] Login from [REDACTED]
+func (l *Logger) WithRedactor(redactor *Redactor) *Logger {
+	return &Logger{
+		level:             l.level,
+		writers:           l.writers,
+		prefixes:          l.prefixes,
+		errorHandler:      l.errorHandler,
+		concurrency:       l.concurrency,
+		fields:            l.fields.Copy(),
+		fieldsFormatter:   l.fieldsFormatter,
+		conditions:        l.conditions,
+		levelConditions:   l.levelConditions,
+		contextConditions: l.contextConditions,
+		exitFunc:          l.exitFunc,
+		exitCode:          l.exitCode,
+		exitTimeout:       l.exitTimeout,
+		exitHandlers:      l.exitHandlers,
+		repanic:           l.repanic,
+		beforeHooks:       l.beforeHooks,
+		afterHooks:        l.afterHooks,
+		processors:        l.processors,
+		redactor:          redactor,
+		encoder:           l.encoder,
+		name:              l.name,
+		reportCaller:      l.reportCaller,
+		ctx:               l.ctx,
+		multiline:         l.multiline,
+	}
+}
+
+// Redact masks sensitive data in the record's message and fields in place.
+func (r *Redactor) Redact(record *Record) {
+	record.Message = r.RedactString(record.Message)
+	record.Fields = r.RedactFields(record.Fields)
+}
+
+// RedactString replaces every detector match in s with the mask.
+func (r *Redactor) RedactString(s string) string {
+	mask := r.mask()
+	for _, detector := range r.Detectors {
+		if detector.Pattern == nil {
+			continue
+		}
+		s = detector.Pattern.ReplaceAllStringFunc(s, func(match string) string {
+			if detector.Validate != nil && !detector.Validate(match) {
+				return match
+			}
+			return mask
+		})
+	}
+	return s
+}
+
+// RedactFields returns a redacted copy of fields. The original fields are not modified.
+func (r *Redactor) RedactFields(fields Fields) Fields {
+	if fields == nil {
+		return nil
+	}
+
+	redacted := make(Fields, len(fields))
+	for k, v := range fields {
+		if r.matchKey(k) {
+			redacted[k] = r.mask()
+			continue
+		}
+		redacted[k] = r.redactValue(v, 0)
+	}
+	return redacted
+}
+
+func (r *Redactor) mask() string {
+	if r.Mask == "" {
+		return DefaultRedactionMask
+	}
+	return r.Mask
+}
+
+func (r *Redactor) matchKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, pattern := range r.Keys {
+		if matched, err := path.Match(strings.ToLower(pattern), key); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// redactValue walks a field value and returns a redacted copy of it.
+// Values which need no redaction are returned unchanged.
+func (r *Redactor) redactValue(value interface{}, depth int) interface{} {
+	redacted, _ := r.redact(value, depth)
+	return redacted
+}
+
+// redact returns a redacted copy of value and whether anything was redacted.
+//
+// Structs, maps, slices and pointers are walked first, so sensitive keys are
+// found even in types with a String or Error method. The String or Error
+// output is only scrubbed when the walk found nothing, for example for types
+// without exported fields such as errors created with errors.New.
+func (r *Redactor) redact(value interface{}, depth int) (interface{}, bool) {
+	maxDepth := r.MaxDepth
+	if maxDepth <= 0 {
+		maxDepth = DefaultRedactionMaxDepth
+	}
+	if value == nil || depth > maxDepth {
+		return value, false
+	}
+
+	if s, ok := value.(string); ok {
+		redacted := r.RedactString(s)
+		return redacted, redacted != s
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice, reflect.Array, reflect.Struct:
+		walked, changed := r.redactComposite(rv, depth)
+		if changed {
+			return walked, true
+		}
+		if redacted, ok := r.redactText(value); ok {
+			return redacted, true
+		}
+		return value, false
+	}
+
+	if redacted, ok := r.redactText(value); ok {
+		return redacted, true
+	}
+	if rv.Kind() == reflect.String {
+		return r.redact(rv.String(), depth)
+	}
+	return value, false
+}
+
+// redactText scrubs the Error or String output of value.
+// It reports false when value has neither method or nothing was redacted.
+func (r *Redactor) redactText(value interface{}) (interface{}, bool) {
+	switch v := value.(type) {
+	case error:
+		if redacted := r.RedactString(v.Error()); redacted != v.Error() {
+			return errors.New(redacted), true
+		}
+	case fmt.Stringer:
+		if redacted := r.RedactString(v.String()); redacted != v.String() {
+			return redacted, true
+		}
+	}
+	return nil, false
+}
+
+// redactComposite walks pointers, maps, slices, arrays and structs.
+func (r *Redactor) redactComposite(rv reflect.Value, depth int) (interface{}, bool) {
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if rv.IsNil() {
+			return rv.Interface(), false
+		}
+		return r.redact(rv.Elem().Interface(), depth+1)
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return rv.Interface(), false
+		}
+		changed := false
+		redacted := make(map[string]interface{}, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			key := iter.Key().String()
+			if r.matchKey(key) {
+				redacted[key] = r.mask()
+				changed = true
+				continue
+			}
+			value, c := r.redact(iter.Value().Interface(), depth+1)
+			redacted[key] = value
+			changed = changed || c
+		}
+		return redacted, changed
+	case reflect.Slice, reflect.Array:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return rv.Interface(), false
+		}
+		changed := false
+		redacted := make([]interface{}, rv.Len())
+		for i := range rv.Len() {
+			value, c := r.redact(rv.Index(i).Interface(), depth+1)
+			redacted[i] = value
+			changed = changed || c
+		}
+		return redacted, changed
+	default:
+		return r.redactStruct(rv, depth)
+	}
+}
+
+// redactStruct converts a struct into a map of its exported fields,
+// keyed by json tag name when present, and redacts the values.
+func (r *Redactor) redactStruct(rv reflect.Value, depth int) (interface{}, bool) {
+	rt := rv.Type()
+	changed := false
+	redacted := make(map[string]interface{}, rt.NumField())
+	for i := range rt.NumField() {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := field.Name
+		if tag, ok := field.Tag.Lookup("json"); ok {
+			tagName, _, _ := strings.Cut(tag, ",")
+			if tagName == "-" {
+				continue
+			}
+			if tagName != "" {
+				name = tagName
+			}
+		}
+
+		if r.matchKey(name) {
+			redacted[name] = r.mask()
+			changed = true
+			continue
+		}
+		value, c := r.redact(rv.Field(i).Interface(), depth+1)
+		redacted[name] = value
+		changed = changed || c
+	}
+	return redacted, changed
+}
+
+// luhnValid reports whether the digits in s pass the Luhn checksum.
+func luhnValid(s string) bool {
+	sum := 0
+	digits := 0
+	double := false
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		digits++
+		double = !double
+	}
+	return digits >= 13 && digits <= 19 && sum%10 == 0
+}
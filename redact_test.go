@@ -0,0 +1,230 @@
+package balogan
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRedactor_RedactString(t *testing.T) {
+	r := NewDefaultRedactor()
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"email", "contact john.doe@example.com now", "contact [REDACTED] now"},
+		{"card", "card 4111 1111 1111 1111 charged", "card [REDACTED] charged"},
+		{"card with dashes", "card 4111-1111-1111-1111", "card [REDACTED]"},
+		{"invalid luhn", "order 1234567890123 shipped", "order 1234567890123 shipped"},
+		{"bearer", "Authorization: Bearer abc.def-ghi", "Authorization: [REDACTED]"},
+		{"clean", "nothing to hide", "nothing to hide"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := r.RedactString(tt.input); got != tt.expected {
+				t.Errorf("RedactString(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRedactor_KeyPatterns(t *testing.T) {
+	r := &Redactor{Keys: []string{"password", "*token*", "authorization"}, Mask: "***"}
+
+	fields := Fields{
+		"password":      "hunter2",
+		"Password":      "hunter2",
+		"access_token":  "abc",
+		"tokenizer":     "abc",
+		"Authorization": "Basic xyz",
+		"user":          "john",
+	}
+
+	redacted := r.RedactFields(fields)
+	for _, key := range []string{"password", "Password", "access_token", "tokenizer", "Authorization"} {
+		if redacted[key] != "***" {
+			t.Errorf("Field %q should be masked, got %v", key, redacted[key])
+		}
+	}
+	if redacted["user"] != "john" {
+		t.Errorf("Field user should be kept, got %v", redacted["user"])
+	}
+	if fields["password"] != "hunter2" {
+		t.Error("RedactFields should not modify the original fields")
+	}
+}
+
+type redactTestUser struct {
+	Name     string            `json:"name"`
+	Password string            `json:"password"`
+	Email    string            `json:"email"`
+	Roles    map[string]string `json:"roles"`
+	Ignored  string            `json:"-"`
+	internal string
+}
+
+func TestRedactor_NestedValues(t *testing.T) {
+	r := NewDefaultRedactor()
+
+	fields := Fields{
+		"user": &redactTestUser{
+			Name:     "john",
+			Password: "hunter2",
+			Email:    "john@example.com",
+			Roles:    map[string]string{"admin": "yes"},
+			Ignored:  "skip",
+			internal: "hidden",
+		},
+		"request": map[string]interface{}{
+			"headers": map[string]string{"Authorization": "Bearer abc"},
+			"emails":  []string{"a@b.io", "plain"},
+		},
+		"err": errors.New("failed for mary@example.org"),
+	}
+
+	redacted := r.RedactFields(fields)
+
+	user, ok := redacted["user"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Struct should be converted to map, got %T", redacted["user"])
+	}
+	if user["name"] != "john" || user["password"] != DefaultRedactionMask || user["email"] != DefaultRedactionMask {
+		t.Errorf("Unexpected redacted struct: %v", user)
+	}
+	if roles, ok := user["roles"].(map[string]string); !ok || roles["admin"] != "yes" {
+		t.Errorf("Nested map without sensitive keys should be kept unchanged, got %#v", user["roles"])
+	}
+	if _, exists := user["-"]; exists {
+		t.Error("Fields tagged json:\"-\" should be skipped")
+	}
+	if _, exists := user["internal"]; exists {
+		t.Error("Unexported fields should be skipped")
+	}
+
+	request := redacted["request"].(map[string]interface{})
+	headers := request["headers"].(map[string]interface{})
+	if headers["Authorization"] != DefaultRedactionMask {
+		t.Errorf("Nested sensitive key should be masked, got %v", headers)
+	}
+	emails := request["emails"].([]interface{})
+	if emails[0] != DefaultRedactionMask || emails[1] != "plain" {
+		t.Errorf("Slice values should be scrubbed, got %v", emails)
+	}
+
+	if err, ok := redacted["err"].(error); !ok || err.Error() != "failed for [REDACTED]" {
+		t.Errorf("Error values should be scrubbed, got %v", redacted["err"])
+	}
+}
+
+type redactTestStringer struct {
+	Name     string `json:"name"`
+	Password string `json:"password"`
+}
+
+func (u redactTestStringer) String() string {
+	return u.Name
+}
+
+type redactTestError struct {
+	Op    string `json:"op"`
+	Token string `json:"token"`
+}
+
+func (e *redactTestError) Error() string {
+	return e.Op + " failed"
+}
+
+func TestLogger_WithRedactorStringerStruct(t *testing.T) {
+	mockWriter := &MockWriter{}
+	logger := New(InfoLevel, mockWriter).
+		WithJSON().
+		WithRedactor(NewDefaultRedactor()).
+		WithFields(Fields{
+			"user": redactTestStringer{Name: "john", Password: "hunter2"},
+			"err":  &redactTestError{Op: "login", Token: "abc123"},
+			"at":   time.Date(2024, 12, 13, 0, 0, 0, 0, time.UTC),
+		})
+
+	logger.Info("login")
+
+	output := mockWriter.String()
+	if strings.Contains(output, "hunter2") || strings.Contains(output, "abc123") {
+		t.Errorf("Sensitive struct fields leaked through String/Error methods: %q", output)
+	}
+	if !strings.Contains(output, `"name":"john"`) || !strings.Contains(output, `"op":"login"`) {
+		t.Errorf("Expected the other struct fields to be kept, got %q", output)
+	}
+	if !strings.Contains(output, `"at":"2024-12-13T00:00:00Z"`) {
+		t.Errorf("Values without sensitive data should be kept unchanged, got %q", output)
+	}
+}
+
+type redactTestProfile struct {
+	Name   string
+	Age    int
+	secret string
+}
+
+func TestLogger_WithRedactorKeepsPlainValues(t *testing.T) {
+	fields := Fields{
+		"user": redactTestProfile{Name: "john", Age: 30, secret: "x"},
+		"tags": map[string]string{"team": "core"},
+	}
+
+	plain := &MockWriter{}
+	New(InfoLevel, plain).WithFields(fields).Info("profile")
+	redacted := &MockWriter{}
+	New(InfoLevel, redacted).WithRedactor(NewDefaultRedactor()).WithFields(fields).Info("profile")
+
+	if plain.String() != redacted.String() {
+		t.Errorf("Values without sensitive data should be formatted unchanged, got %q, want %q", redacted.String(), plain.String())
+	}
+}
+
+func TestLogger_WithRedactorFormatters(t *testing.T) {
+	tests := []struct {
+		name      string
+		formatter FieldsFormatter
+	}{
+		{"KeyValue", &KeyValueFormatter{}},
+		{"JSON", &JSONFormatter{}},
+		{"Logfmt", &LogfmtFormatter{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockWriter := &MockWriter{}
+			logger := New(InfoLevel, mockWriter).
+				WithFieldsFormatter(tt.formatter).
+				WithRedactor(NewDefaultRedactor()).
+				WithFields(Fields{"password": "hunter2", "user": "john"})
+
+			logger.Info("login from john@example.com")
+
+			output := mockWriter.String()
+			if strings.Contains(output, "hunter2") || strings.Contains(output, "john@example.com") {
+				t.Errorf("Sensitive data leaked: %q", output)
+			}
+			if !strings.Contains(output, "john") || !strings.Contains(output, DefaultRedactionMask) {
+				t.Errorf("Expected redacted output, got %q", output)
+			}
+		})
+	}
+}
+
+func TestLogger_WithRedactorAfterProcessors(t *testing.T) {
+	mockWriter := &MockWriter{}
+	logger := New(InfoLevel, mockWriter).
+		WithRedactor(&Redactor{Keys: []string{"secret"}}).
+		WithProcessor(AddField("secret", "added-late"))
+
+	logger.Info("message")
+
+	if strings.Contains(mockWriter.String(), "added-late") {
+		t.Errorf("Fields added by processors should be redacted, got %q", mockWriter.String())
+	}
+}
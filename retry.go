@@ -0,0 +1,208 @@
+package balogan
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// maxHTTPErrorBody limits how much of an error response is kept in HTTPError.
+const maxHTTPErrorBody = 1024
+
+// HTTPError is returned by the HTTP based writers when a server
+// responds with a status code outside of the 2xx range.
+type HTTPError struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+	// Body holds the beginning of the response body.
+	Body string
+	// RetryAfter is the delay requested by the Retry-After header, or zero.
+	RetryAfter time.Duration
+}
+
+func (e *HTTPError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("http status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("http status %d: %s", e.StatusCode, e.Body)
+}
+
+// Temporary reports whether the request may succeed when retried:
+// on 408 Request Timeout, 429 Too Many Requests and 5xx server errors.
+func (e *HTTPError) Temporary() bool {
+	return e.StatusCode == http.StatusRequestTimeout ||
+		e.StatusCode == http.StatusTooManyRequests ||
+		e.StatusCode >= 500
+}
+
+// permanentError marks an error which must not be retried.
+type permanentError struct{ err error }
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// defaultRetryMaxBackoff caps the delay of a retryPolicy without maxBackoff.
+const defaultRetryMaxBackoff = 30 * time.Second
+
+// retryPolicy retries failed deliveries with exponential backoff.
+type retryPolicy struct {
+	// maxRetries is the number of retries after the first attempt.
+	maxRetries int
+	// backoff is the delay before the first retry. It doubles for every further retry.
+	backoff time.Duration
+	// maxBackoff caps the delay. defaultRetryMaxBackoff is used when zero.
+	maxBackoff time.Duration
+	// jitter randomizes every delay between half and the full value.
+	jitter bool
+}
+
+// do calls fn until it succeeds, returns an error which is not retryable,
+// or the retries are exhausted. The last error is returned.
+func (p retryPolicy) do(ctx context.Context, fn func() error) error {
+	var err error
+	for attempt := 0; ; attempt++ {
+		if err = fn(); err == nil || attempt >= p.maxRetries || !retryable(err) {
+			return err
+		}
+
+		if waitErr := p.wait(ctx, attempt, err); waitErr != nil {
+			return errors.Join(err, waitErr)
+		}
+	}
+}
+
+// wait sleeps before the retry following attempt, which failed with err.
+// It returns the context's error when ctx is done first.
+func (p retryPolicy) wait(ctx context.Context, attempt int, err error) error {
+	timer := time.NewTimer(p.delay(attempt, err))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// delay returns the wait before the retry after attempt. A Retry-After of the
+// server is honoured, but capped at maxBackoff like the exponential backoff,
+// so a server cannot stall the writer for hours.
+func (p retryPolicy) delay(attempt int, err error) time.Duration {
+	limit := p.maxBackoff
+	if limit <= 0 {
+		limit = defaultRetryMaxBackoff
+	}
+
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) && httpErr.RetryAfter > 0 {
+		return min(httpErr.RetryAfter, limit)
+	}
+
+	// The shift is only done when its result stays within the limit, so it cannot overflow.
+	delay := limit
+	if attempt < 63 && p.backoff <= limit>>attempt {
+		delay = p.backoff << attempt
+	}
+	if p.jitter && delay > 1 {
+		delay = delay/2 + rand.N(delay/2)
+	}
+	return delay
+}
+
+// retryable reports whether a failed delivery should be retried.
+// Network errors and temporary HTTP errors are retried.
+func retryable(err error) bool {
+	var permanent *permanentError
+	if errors.As(err, &permanent) {
+		return false
+	}
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.Temporary()
+	}
+	return !errors.Is(err, context.Canceled)
+}
+
+// httpRequest describes a request sent by the HTTP based writers.
+type httpRequest struct {
+	method  string
+	url     string
+	headers map[string]string
+	body    []byte
+	gzip    bool
+}
+
+// send performs the request and returns the response body.
+// A status code outside of the 2xx range is returned as *HTTPError.
+func (r httpRequest) send(ctx context.Context, client *http.Client, timeout time.Duration) ([]byte, error) {
+	body := r.body
+	if r.gzip {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(body); err != nil {
+			return nil, err
+		}
+		if err := zw.Close(); err != nil {
+			return nil, err
+		}
+		body = buf.Bytes()
+	}
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, r.method, r.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, &permanentError{err}
+	}
+	for k, v := range r.headers {
+		req.Header.Set(k, v)
+	}
+	if r.gzip {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		if len(respBody) > maxHTTPErrorBody {
+			respBody = respBody[:maxHTTPErrorBody]
+		}
+		return nil, &HTTPError{
+			StatusCode: resp.StatusCode,
+			Body:       string(bytes.TrimSpace(respBody)),
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		}
+	}
+	return respBody, err
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an HTTP date.
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0)
+	}
+	return 0
+}
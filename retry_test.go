@@ -0,0 +1,225 @@
+package balogan
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordedRequest is a request received by a recordingServer.
+type recordedRequest[T any] struct {
+	method string
+	path   string
+	header http.Header
+	body   T
+	// status is the status code the server answered with.
+	status int
+}
+
+// recordingServer is a stub endpoint for the tests of the HTTP based writers.
+// It records every request with its body, decompressed and decoded by the
+// protocol's decode function. The first failures requests are answered with
+// status and retryAfter, the others with respond, or 200 OK when respond is nil.
+type recordingServer[T any] struct {
+	*httptest.Server
+
+	mu         sync.Mutex
+	requests   []recordedRequest[T]
+	failures   int
+	status     int
+	retryAfter string
+	respond    func(w http.ResponseWriter, request int, body T)
+}
+
+func newRecordingServer[T any](t *testing.T, decode func(body []byte) (T, error)) *recordingServer[T] {
+	s := &recordingServer[T]{status: http.StatusServiceUnavailable}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		body := io.Reader(r.Body)
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			zr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				t.Errorf("Invalid gzip body: %v", err)
+				return
+			}
+			body = zr
+		}
+		data, _ := io.ReadAll(body)
+
+		req := recordedRequest[T]{method: r.Method, path: r.URL.Path, header: r.Header.Clone(), status: http.StatusOK}
+		var err error
+		if req.body, err = decode(data); err != nil {
+			t.Errorf("Invalid request body %q: %v", data, err)
+		}
+
+		if s.failures > 0 {
+			s.failures--
+			req.status = s.status
+			s.requests = append(s.requests, req)
+			if s.retryAfter != "" {
+				w.Header().Set("Retry-After", s.retryAfter)
+			}
+			w.WriteHeader(s.status)
+			return
+		}
+
+		s.requests = append(s.requests, req)
+		if s.respond != nil {
+			s.respond(w, len(s.requests)-1, req.body)
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// captured returns every request received so far.
+func (s *recordingServer[T]) captured() []recordedRequest[T] {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]recordedRequest[T](nil), s.requests...)
+}
+
+// accepted returns the bodies of the requests which were not failed.
+func (s *recordingServer[T]) accepted() []T {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var bodies []T
+	for _, req := range s.requests {
+		if req.status < 300 {
+			bodies = append(bodies, req.body)
+		}
+	}
+	return bodies
+}
+
+// remainingFailures returns the number of requests still to be failed.
+func (s *recordingServer[T]) remainingFailures() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.failures
+}
+
+func TestRetryPolicy_Do(t *testing.T) {
+	policy := retryPolicy{maxRetries: 3, backoff: time.Millisecond}
+
+	attempts := 0
+	err := policy.do(context.Background(), func() error {
+		attempts++
+		if attempts < 3 {
+			return &HTTPError{StatusCode: http.StatusServiceUnavailable}
+		}
+		return nil
+	})
+	if err != nil || attempts != 3 {
+		t.Errorf("Expected success after 3 attempts, got %v after %d", err, attempts)
+	}
+
+	attempts = 0
+	err = policy.do(context.Background(), func() error {
+		attempts++
+		return &HTTPError{StatusCode: http.StatusBadRequest}
+	})
+	if err == nil || attempts != 1 {
+		t.Errorf("Client errors should not be retried, got %d attempts", attempts)
+	}
+
+	attempts = 0
+	err = policy.do(context.Background(), func() error {
+		attempts++
+		return errors.New("connection refused")
+	})
+	if err == nil || attempts != 4 {
+		t.Errorf("Expected 1 attempt and 3 retries, got %d attempts", attempts)
+	}
+}
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	policy := retryPolicy{backoff: 100 * time.Millisecond, maxBackoff: time.Second}
+
+	if d := policy.delay(0, errors.New("x")); d != 100*time.Millisecond {
+		t.Errorf("Expected initial backoff, got %v", d)
+	}
+	if d := policy.delay(2, errors.New("x")); d != 400*time.Millisecond {
+		t.Errorf("Expected doubled backoff, got %v", d)
+	}
+	if d := policy.delay(10, errors.New("x")); d != time.Second {
+		t.Errorf("Expected capped backoff, got %v", d)
+	}
+	if d := policy.delay(100, errors.New("x")); d != time.Second {
+		t.Errorf("Expected a large attempt to be capped at maxBackoff, got %v", d)
+	}
+	if d := policy.delay(0, &HTTPError{StatusCode: 429, RetryAfter: 500 * time.Millisecond}); d != 500*time.Millisecond {
+		t.Errorf("Expected Retry-After delay, got %v", d)
+	}
+	if d := policy.delay(0, &HTTPError{StatusCode: 429, RetryAfter: time.Hour}); d != time.Second {
+		t.Errorf("Expected Retry-After to be capped at the max backoff, got %v", d)
+	}
+
+	policy.jitter = true
+	for range 10 {
+		if d := policy.delay(0, errors.New("x")); d < 50*time.Millisecond || d > 100*time.Millisecond {
+			t.Errorf("Jittered delay out of range: %v", d)
+		}
+	}
+}
+
+func TestRetryPolicy_DelayDefaultCap(t *testing.T) {
+	policy := retryPolicy{backoff: 500 * time.Millisecond}
+
+	for _, attempt := range []int{10, 40, 62, 63, 64, 1000} {
+		if d := policy.delay(attempt, errors.New("x")); d != defaultRetryMaxBackoff {
+			t.Errorf("Expected attempt %d to be capped at %v, got %v", attempt, defaultRetryMaxBackoff, d)
+		}
+	}
+	if d := policy.delay(0, &HTTPError{StatusCode: 503, RetryAfter: time.Hour}); d != defaultRetryMaxBackoff {
+		t.Errorf("Expected Retry-After to be capped at %v, got %v", defaultRetryMaxBackoff, d)
+	}
+}
+
+func TestHTTPRequest_Send(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Test") != "yes" {
+			t.Errorf("Expected custom header")
+		}
+		w.Header().Set("Retry-After", "7")
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte("slow down\n"))
+	}))
+	defer server.Close()
+
+	req := httpRequest{method: http.MethodPost, url: server.URL, headers: map[string]string{"X-Test": "yes"}}
+	_, err := req.send(context.Background(), http.DefaultClient, time.Second)
+
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) {
+		t.Fatalf("Expected HTTPError, got %v", err)
+	}
+	if httpErr.StatusCode != 429 || httpErr.Body != "slow down" || httpErr.RetryAfter != 7*time.Second {
+		t.Errorf("Unexpected error %+v", httpErr)
+	}
+	if !httpErr.Temporary() {
+		t.Error("429 should be temporary")
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	if d := parseRetryAfter("120"); d != 2*time.Minute {
+		t.Errorf("Expected 2m, got %v", d)
+	}
+	date := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	if d := parseRetryAfter(date); d < 59*time.Minute || d > time.Hour {
+		t.Errorf("Expected about 1h, got %v", d)
+	}
+	if d := parseRetryAfter("soon"); d != 0 {
+		t.Errorf("Expected 0 for invalid value, got %v", d)
+	}
+}
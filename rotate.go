@@ -0,0 +1,395 @@
+package balogan
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rotationTimeFormat is the timestamp format of backup file names.
+const rotationTimeFormat = "2006-01-02T15-04-05.000"
+
+// RotatingFileConfig configures a RotatingFileLogWriter.
+type RotatingFileConfig struct {
+	// Filename is the path of the active log file.
+	Filename string
+
+	// MaxSize rotates the file before it grows beyond MaxSize bytes.
+	// The file is not rotated by size when zero.
+	MaxSize int64
+	// RotateEvery rotates the file at every multiple of the interval, for example
+	// every hour or every 24 hours. The file is not rotated by time when zero.
+	RotateEvery time.Duration
+
+	// MaxBackups is the number of backups to keep. All backups are kept when zero.
+	MaxBackups int
+	// MaxAge removes backups older than the duration. Backups are kept when zero.
+	MaxAge time.Duration
+	// Compress gzips backups in the background.
+	Compress bool
+	// LocalTime uses the local time in backup names instead of UTC.
+	LocalTime bool
+	// Mode is the permission of new log files and compressed backups, before the umask.
+	// DefaultFileMode is used when zero.
+	Mode os.FileMode
+}
+
+// RotatingFileLogWriter writes log messages to a file which is rotated by size or time.
+//
+// On rotation, the active file is renamed to a backup named after the rotation
+// time, for example "app-2024-12-13T15-30-45.000.log", and a new file is created.
+// Compressing backups and removing those beyond MaxBackups or MaxAge happens in
+// the background. Errors of the background work are returned by the next Write.
+//
+// Writes are serialized, so the writer is safe for concurrent use.
+// Like FileLogWriter, every message is terminated with a newline.
+type RotatingFileLogWriter struct {
+	mu           sync.Mutex
+	cfg          RotatingFileConfig
+	file         *os.File
+	size         int64
+	nextRotation time.Time
+	closed       bool
+
+	errMu sync.Mutex
+	errs  []error
+
+	mill     chan struct{}
+	millDone chan struct{}
+}
+
+// NewRotatingFileLogWriter creates a new RotatingFileLogWriter and opens the log file.
+// An existing file is appended to.
+func NewRotatingFileLogWriter(cfg *RotatingFileConfig) (*RotatingFileLogWriter, error) {
+	if cfg == nil || cfg.Filename == "" {
+		return nil, errors.New("rotating file writer: filename is required")
+	}
+	if cfg.MaxSize < 0 || cfg.RotateEvery < 0 || cfg.MaxBackups < 0 || cfg.MaxAge < 0 {
+		return nil, errors.New("rotating file writer: limits must not be negative")
+	}
+
+	c := *cfg
+	if c.Mode == 0 {
+		c.Mode = DefaultFileMode
+	}
+
+	w := &RotatingFileLogWriter{
+		cfg:      c,
+		mill:     make(chan struct{}, 1),
+		millDone: make(chan struct{}),
+	}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+
+	go w.runMill()
+	w.triggerMill()
+	return w, nil
+}
+
+func (w *RotatingFileLogWriter) Write(bytes []byte) (int, error) {
+	line := bytes
+	if len(line) == 0 || line[len(line)-1] != '\n' {
+		line = append(line[:len(line):len(line)], '\n')
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return 0, os.ErrClosed
+	}
+	if w.file == nil {
+		// A previous rotation could not open the new file, try again.
+		if err := w.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	// A failed rotation is reported, but the message still goes to the active file.
+	var rotateErr error
+	if w.shouldRotate(int64(len(line))) {
+		rotateErr = w.rotate()
+		if w.file == nil {
+			return 0, rotateErr
+		}
+	}
+
+	n, err := w.file.Write(line)
+	w.size += int64(n)
+	if err != nil {
+		return 0, errors.Join(rotateErr, err)
+	}
+	return len(bytes), errors.Join(rotateErr, w.takeErrors())
+}
+
+// Rotate rotates the log file immediately.
+func (w *RotatingFileLogWriter) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return os.ErrClosed
+	}
+	return w.rotate()
+}
+
+// Close closes the log file and waits for background compression and cleanup.
+func (w *RotatingFileLogWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return os.ErrClosed
+	}
+	w.closed = true
+	var err error
+	if w.file != nil {
+		err = w.file.Close()
+	}
+	w.mu.Unlock()
+
+	close(w.mill)
+	<-w.millDone
+
+	return errors.Join(err, w.takeErrors())
+}
+
+func (w *RotatingFileLogWriter) shouldRotate(n int64) bool {
+	if w.cfg.MaxSize > 0 && w.size > 0 && w.size+n > w.cfg.MaxSize {
+		return true
+	}
+	if w.cfg.RotateEvery <= 0 {
+		return false
+	}
+
+	now := time.Now()
+	if now.Before(w.nextRotation) {
+		return false
+	}
+	if w.size == 0 {
+		// Nothing to rotate, wait for the next interval.
+		w.nextRotation = now.Truncate(w.cfg.RotateEvery).Add(w.cfg.RotateEvery)
+		return false
+	}
+	return true
+}
+
+func (w *RotatingFileLogWriter) open() error {
+	file, err := os.OpenFile(w.cfg.Filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, w.cfg.Mode)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	w.file = file
+	w.size = info.Size()
+	if w.cfg.RotateEvery > 0 {
+		w.nextRotation = time.Now().Truncate(w.cfg.RotateEvery).Add(w.cfg.RotateEvery)
+	}
+	return nil
+}
+
+// rotate renames the active file to a backup and opens a new file.
+// When the new file cannot be opened, w.file is left nil and the next
+// write tries to open it again. The caller must hold w.mu.
+func (w *RotatingFileLogWriter) rotate() error {
+	if w.file != nil {
+		err := w.file.Close()
+		w.file = nil
+		if err != nil {
+			return err
+		}
+	}
+
+	backup := w.backupName(time.Now())
+	if err := os.Rename(w.cfg.Filename, backup); err != nil && !errors.Is(err, os.ErrNotExist) {
+		// Keep writing to the active file rather than losing messages.
+		if openErr := w.open(); openErr != nil {
+			return errors.Join(err, openErr)
+		}
+		return err
+	}
+
+	if err := w.open(); err != nil {
+		return err
+	}
+	w.triggerMill()
+	return nil
+}
+
+// backupName returns a free backup file name for the rotation time t.
+// Names which cannot be checked are returned too, so the rename reports the error.
+func (w *RotatingFileLogWriter) backupName(t time.Time) string {
+	if !w.cfg.LocalTime {
+		t = t.UTC()
+	}
+
+	prefix, ext := w.nameParts()
+	for {
+		name := prefix + t.Format(rotationTimeFormat) + ext
+		if _, err := os.Lstat(name); err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				return name
+			}
+			if _, err := os.Lstat(name + ".gz"); err != nil {
+				return name
+			}
+		}
+		t = t.Add(time.Millisecond)
+	}
+}
+
+// nameParts splits the file name into the backup prefix ("dir/app-") and extension (".log").
+func (w *RotatingFileLogWriter) nameParts() (prefix, ext string) {
+	ext = filepath.Ext(w.cfg.Filename)
+	return strings.TrimSuffix(w.cfg.Filename, ext) + "-", ext
+}
+
+func (w *RotatingFileLogWriter) triggerMill() {
+	select {
+	case w.mill <- struct{}{}:
+	default:
+	}
+}
+
+func (w *RotatingFileLogWriter) runMill() {
+	defer close(w.millDone)
+
+	for range w.mill {
+		if err := w.millOnce(); err != nil {
+			w.errMu.Lock()
+			w.errs = append(w.errs, err)
+			w.errMu.Unlock()
+		}
+	}
+}
+
+func (w *RotatingFileLogWriter) takeErrors() error {
+	w.errMu.Lock()
+	defer w.errMu.Unlock()
+
+	err := errors.Join(w.errs...)
+	w.errs = nil
+	return err
+}
+
+type rotatedBackup struct {
+	path string
+	time time.Time
+}
+
+// millOnce compresses backups and removes those beyond MaxBackups or MaxAge.
+func (w *RotatingFileLogWriter) millOnce() error {
+	if !w.cfg.Compress && w.cfg.MaxBackups == 0 && w.cfg.MaxAge == 0 {
+		return nil
+	}
+
+	backups, err := w.backups()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	cutoff := time.Now().Add(-w.cfg.MaxAge)
+	for i, backup := range backups {
+		tooMany := w.cfg.MaxBackups > 0 && i >= w.cfg.MaxBackups
+		tooOld := w.cfg.MaxAge > 0 && backup.time.Before(cutoff)
+		switch {
+		case tooMany || tooOld:
+			if err := os.Remove(backup.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				errs = append(errs, err)
+			}
+		case w.cfg.Compress && !strings.HasSuffix(backup.path, ".gz"):
+			if err := compressFile(backup.path, w.cfg.Mode); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// backups returns the backups of the log file, newest first.
+func (w *RotatingFileLogWriter) backups() ([]rotatedBackup, error) {
+	prefix, ext := w.nameParts()
+	dir := filepath.Dir(w.cfg.Filename)
+	base := filepath.Base(prefix)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	location := time.UTC
+	if w.cfg.LocalTime {
+		location = time.Local
+	}
+
+	var backups []rotatedBackup
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, base) {
+			continue
+		}
+
+		stamp := strings.TrimSuffix(strings.TrimPrefix(name, base), ".gz")
+		if !strings.HasSuffix(stamp, ext) {
+			continue
+		}
+		t, err := time.ParseInLocation(rotationTimeFormat, strings.TrimSuffix(stamp, ext), location)
+		if err != nil {
+			continue
+		}
+		backups = append(backups, rotatedBackup{path: filepath.Join(dir, name), time: t})
+	}
+
+	sort.Slice(backups, func(i, j int) bool { return backups[i].time.After(backups[j].time) })
+	return backups, nil
+}
+
+// compressFile gzips path into path.gz with the given mode and removes path.
+func compressFile(path string, mode os.FileMode) (err error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	tmp := path + ".gz.tmp"
+	dst, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			dst.Close()
+			os.Remove(tmp)
+		}
+	}()
+
+	zw := gzip.NewWriter(dst)
+	if _, err = io.Copy(zw, src); err != nil {
+		return fmt.Errorf("compressing %s: %w", path, err)
+	}
+	if err = zw.Close(); err != nil {
+		return err
+	}
+	if err = dst.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(tmp, path+".gz"); err != nil {
+		return err
+	}
+	src.Close()
+	return os.Remove(path)
+}
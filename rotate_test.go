@@ -0,0 +1,353 @@
+package balogan
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func listBackups(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir failed: %v", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.Name() != "app.log" {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestRotatingFileLogWriter_MaxSize(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+
+	writer, err := NewRotatingFileLogWriter(&RotatingFileConfig{Filename: filename, MaxSize: 20})
+	if err != nil {
+		t.Fatalf("NewRotatingFileLogWriter failed: %v", err)
+	}
+	var _ LogWriter = writer
+
+	for _, msg := range []string{"first line", "second line", "third line"} {
+		if _, err := writer.Write([]byte(msg)); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(filename)
+	if string(data) != "third line\n" {
+		t.Errorf("Expected only the last line in the active file, got %q", data)
+	}
+
+	backups := listBackups(t, dir)
+	if len(backups) != 2 {
+		t.Fatalf("Expected 2 backups, got %v", backups)
+	}
+	for _, name := range backups {
+		if !strings.HasPrefix(name, "app-") || !strings.HasSuffix(name, ".log") {
+			t.Errorf("Unexpected backup name %q", name)
+		}
+		info, _ := os.Stat(filepath.Join(dir, name))
+		if info.Size() > 20 {
+			t.Errorf("Backup %s exceeds MaxSize: %d bytes", name, info.Size())
+		}
+	}
+	first, _ := os.ReadFile(filepath.Join(dir, backups[0]))
+	if string(first) != "first line\n" {
+		t.Errorf("Expected the oldest backup to hold the first line, got %q", first)
+	}
+}
+
+func TestRotatingFileLogWriter_MaxBackups(t *testing.T) {
+	dir := t.TempDir()
+	writer, err := NewRotatingFileLogWriter(&RotatingFileConfig{
+		Filename:   filepath.Join(dir, "app.log"),
+		MaxBackups: 2,
+	})
+	if err != nil {
+		t.Fatalf("NewRotatingFileLogWriter failed: %v", err)
+	}
+
+	for i := range 5 {
+		_, _ = writer.Write([]byte{'0' + byte(i)})
+		if err := writer.Rotate(); err != nil {
+			t.Fatalf("Rotate failed: %v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	backups := listBackups(t, dir)
+	if len(backups) != 2 {
+		t.Fatalf("Expected 2 backups to be kept, got %v", backups)
+	}
+	newest, _ := os.ReadFile(filepath.Join(dir, backups[1]))
+	if string(newest) != "4\n" {
+		t.Errorf("Expected the newest backups to be kept, got %q", newest)
+	}
+}
+
+func TestRotatingFileLogWriter_MaxAge(t *testing.T) {
+	dir := t.TempDir()
+	old := filepath.Join(dir, "app-"+time.Now().UTC().Add(-48*time.Hour).Format(rotationTimeFormat)+".log")
+	if err := os.WriteFile(old, []byte("old\n"), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	unrelated := filepath.Join(dir, "app-errors.log")
+	if err := os.WriteFile(unrelated, []byte("keep\n"), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	writer, err := NewRotatingFileLogWriter(&RotatingFileConfig{
+		Filename: filepath.Join(dir, "app.log"),
+		MaxAge:   24 * time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("NewRotatingFileLogWriter failed: %v", err)
+	}
+	_, _ = writer.Write([]byte("recent"))
+	if err := writer.Rotate(); err != nil {
+		t.Fatalf("Rotate failed: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	if _, err := os.Stat(old); !errors.Is(err, os.ErrNotExist) {
+		t.Error("Backups older than MaxAge should be removed")
+	}
+	if _, err := os.Stat(unrelated); err != nil {
+		t.Error("Files which are not backups should be kept")
+	}
+	if backups := listBackups(t, dir); len(backups) != 2 {
+		t.Errorf("Expected the recent backup and the unrelated file, got %v", backups)
+	}
+}
+
+func TestRotatingFileLogWriter_Compress(t *testing.T) {
+	dir := t.TempDir()
+	writer, err := NewRotatingFileLogWriter(&RotatingFileConfig{
+		Filename: filepath.Join(dir, "app.log"),
+		Compress: true,
+	})
+	if err != nil {
+		t.Fatalf("NewRotatingFileLogWriter failed: %v", err)
+	}
+
+	_, _ = writer.Write([]byte("compressed line"))
+	if err := writer.Rotate(); err != nil {
+		t.Fatalf("Rotate failed: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	backups := listBackups(t, dir)
+	if len(backups) != 1 || !strings.HasSuffix(backups[0], ".log.gz") {
+		t.Fatalf("Expected one gzipped backup, got %v", backups)
+	}
+
+	f, err := os.Open(filepath.Join(dir, backups[0]))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("Invalid gzip: %v", err)
+	}
+	data, _ := io.ReadAll(zr)
+	if string(data) != "compressed line\n" {
+		t.Errorf("Unexpected backup content %q", data)
+	}
+}
+
+func TestRotatingFileLogWriter_RotateEvery(t *testing.T) {
+	dir := t.TempDir()
+	writer, err := NewRotatingFileLogWriter(&RotatingFileConfig{
+		Filename:    filepath.Join(dir, "app.log"),
+		RotateEvery: 50 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("NewRotatingFileLogWriter failed: %v", err)
+	}
+
+	_, _ = writer.Write([]byte("before"))
+	time.Sleep(60 * time.Millisecond)
+	_, _ = writer.Write([]byte("after"))
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(filepath.Join(dir, "app.log"))
+	if string(data) != "after\n" {
+		t.Errorf("Expected the file to be rotated after the interval, got %q", data)
+	}
+	if backups := listBackups(t, dir); len(backups) != 1 {
+		t.Errorf("Expected 1 backup, got %v", backups)
+	}
+}
+
+func TestRotatingFileLogWriter_Concurrent(t *testing.T) {
+	dir := t.TempDir()
+	writer, err := NewRotatingFileLogWriter(&RotatingFileConfig{
+		Filename: filepath.Join(dir, "app.log"),
+		MaxSize:  256,
+	})
+	if err != nil {
+		t.Fatalf("NewRotatingFileLogWriter failed: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 20 {
+				if _, err := writer.Write([]byte("concurrent message")); err != nil {
+					t.Errorf("Write failed: %v", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	lines := 0
+	entries, _ := os.ReadDir(dir)
+	for _, entry := range entries {
+		data, _ := os.ReadFile(filepath.Join(dir, entry.Name()))
+		for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+			if line != "concurrent message" {
+				t.Fatalf("Interleaved or corrupted line %q", line)
+			}
+			lines++
+		}
+	}
+	if lines != 200 {
+		t.Errorf("Expected 200 lines across all files, got %d", lines)
+	}
+}
+
+func TestRotatingFileLogWriter_RecoversFromFailedOpen(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "logs")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatalf("Mkdir failed: %v", err)
+	}
+	writer, err := NewRotatingFileLogWriter(&RotatingFileConfig{Filename: filepath.Join(dir, "app.log")})
+	if err != nil {
+		t.Fatalf("NewRotatingFileLogWriter failed: %v", err)
+	}
+	defer writer.Close()
+
+	// Without its directory, the new file cannot be opened after rotation.
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatalf("RemoveAll failed: %v", err)
+	}
+	if err := writer.Rotate(); err == nil {
+		t.Fatal("Expected Rotate to fail without the directory")
+	}
+	if _, err := writer.Write([]byte("lost")); err == nil {
+		t.Fatal("Expected Write to fail while the file cannot be opened")
+	}
+
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatalf("Mkdir failed: %v", err)
+	}
+	if _, err := writer.Write([]byte("recovered")); err != nil {
+		t.Fatalf("Expected Write to reopen the file, got %v", err)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, "app.log"))
+	if string(data) != "recovered\n" {
+		t.Errorf("Unexpected file content %q", data)
+	}
+}
+
+func TestRotatingFileLogWriter_KeepsWritingWhenRenameFails(t *testing.T) {
+	// The backup name exceeds the file name limit, so renaming fails while
+	// the active file can still be opened.
+	filename := filepath.Join(t.TempDir(), strings.Repeat("a", 246)+".log")
+	writer, err := NewRotatingFileLogWriter(&RotatingFileConfig{Filename: filename, MaxSize: 10})
+	if err != nil {
+		t.Fatalf("NewRotatingFileLogWriter failed: %v", err)
+	}
+	defer writer.Close()
+
+	for _, msg := range []string{"first line", "second line", "third line"} {
+		n, err := writer.Write([]byte(msg))
+		if n != len(msg) {
+			t.Errorf("Expected %q to be written, got %d bytes", msg, n)
+		}
+		if msg != "first line" && err == nil {
+			t.Errorf("Expected the failed rotation to be reported for %q", msg)
+		}
+	}
+
+	data, _ := os.ReadFile(filename)
+	if string(data) != "first line\nsecond line\nthird line\n" {
+		t.Errorf("Expected every message in the active file, got %q", data)
+	}
+}
+
+func TestRotatingFileLogWriter_Mode(t *testing.T) {
+	dir := t.TempDir()
+	writer, err := NewRotatingFileLogWriter(&RotatingFileConfig{
+		Filename: filepath.Join(dir, "app.log"),
+		Compress: true,
+		Mode:     0600,
+	})
+	if err != nil {
+		t.Fatalf("NewRotatingFileLogWriter failed: %v", err)
+	}
+	_, _ = writer.Write([]byte("private"))
+	if err := writer.Rotate(); err != nil {
+		t.Fatalf("Rotate failed: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	entries, _ := os.ReadDir(dir)
+	for _, entry := range entries {
+		info, _ := entry.Info()
+		if perm := info.Mode().Perm(); perm != 0600 {
+			t.Errorf("Expected %s to have mode 0600, got %v", entry.Name(), perm)
+		}
+	}
+}
+
+func TestRotatingFileLogWriter_Closed(t *testing.T) {
+	writer, err := NewRotatingFileLogWriter(&RotatingFileConfig{Filename: filepath.Join(t.TempDir(), "app.log")})
+	if err != nil {
+		t.Fatalf("NewRotatingFileLogWriter failed: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if _, err := writer.Write([]byte("late")); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Expected os.ErrClosed, got %v", err)
+	}
+	if err := writer.Close(); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Expected os.ErrClosed on second close, got %v", err)
+	}
+	if _, err := NewRotatingFileLogWriter(&RotatingFileConfig{}); err == nil {
+		t.Error("Expected error without filename")
+	}
+}
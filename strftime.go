@@ -0,0 +1,73 @@
+package balogan
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// strftime formats t with a strftime-style pattern, as used in index names
+// and file paths. Supported directives:
+//
+//	%Y  year (2006)           %y  two digit year (06)
+//	%m  month (01-12)         %d  day of month (01-31)
+//	%H  hour (00-23)          %M  minute (00-59)
+//	%S  second (00-59)        %j  day of year (001-366)
+//	%b  month name (Jan)      %a  weekday name (Mon)
+//	%s  Unix timestamp        %%  a literal '%'
+//
+// Unknown directives are copied unchanged.
+func strftime(pattern string, t time.Time) string {
+	if !strings.Contains(pattern, "%") {
+		return pattern
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		if c != '%' || i+1 == len(pattern) {
+			b.WriteByte(c)
+			continue
+		}
+
+		i++
+		switch pattern[i] {
+		case 'Y':
+			b.WriteString(strconv.Itoa(t.Year()))
+		case 'y':
+			writePadded(&b, t.Year()%100, 2)
+		case 'm':
+			writePadded(&b, int(t.Month()), 2)
+		case 'd':
+			writePadded(&b, t.Day(), 2)
+		case 'H':
+			writePadded(&b, t.Hour(), 2)
+		case 'M':
+			writePadded(&b, t.Minute(), 2)
+		case 'S':
+			writePadded(&b, t.Second(), 2)
+		case 'j':
+			writePadded(&b, t.YearDay(), 3)
+		case 'b':
+			b.WriteString(t.Month().String()[:3])
+		case 'a':
+			b.WriteString(t.Weekday().String()[:3])
+		case 's':
+			b.WriteString(strconv.FormatInt(t.Unix(), 10))
+		case '%':
+			b.WriteByte('%')
+		default:
+			b.WriteByte('%')
+			b.WriteByte(pattern[i])
+		}
+	}
+	return b.String()
+}
+
+func writePadded(b *strings.Builder, n, width int) {
+	s := strconv.Itoa(n)
+	for i := len(s); i < width; i++ {
+		b.WriteByte('0')
+	}
+	b.WriteString(s)
+}
@@ -0,0 +1,24 @@
+package balogan
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStrftime(t *testing.T) {
+	tm := time.Date(2024, time.February, 5, 7, 8, 9, 0, time.UTC)
+
+	tests := map[string]string{
+		"logs-%Y.%m.%d":   "logs-2024.02.05",
+		"%y%j-%H:%M:%S":   "24036-07:08:09",
+		"%a %b":           "Mon Feb",
+		"%s":              "1707116889",
+		"100%% %q done %": "100% %q done %",
+		"static":          "static",
+	}
+	for pattern, expected := range tests {
+		if got := strftime(pattern, tm); got != expected {
+			t.Errorf("strftime(%q) = %q, want %q", pattern, got, expected)
+		}
+	}
+}
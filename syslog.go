@@ -0,0 +1,373 @@
+package balogan
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SyslogFormat selects the syslog message format.
+type SyslogFormat int
+
+const (
+	// RFC5424 is the modern syslog format with structured data.
+	RFC5424 SyslogFormat = iota
+	// RFC3164 is the legacy BSD syslog format. Fields are appended to the message in logfmt style.
+	RFC3164
+)
+
+// SyslogFacility is a syslog facility code.
+type SyslogFacility int
+
+// Syslog facilities as defined by RFC 5424.
+const (
+	FacilityKern SyslogFacility = iota
+	FacilityUser
+	FacilityMail
+	FacilityDaemon
+	FacilityAuth
+	FacilitySyslog
+	FacilityLpr
+	FacilityNews
+	FacilityUucp
+	FacilityCron
+	FacilityAuthPriv
+	FacilityFtp
+	_
+	_
+	_
+	_
+	FacilityLocal0
+	FacilityLocal1
+	FacilityLocal2
+	FacilityLocal3
+	FacilityLocal4
+	FacilityLocal5
+	FacilityLocal6
+	FacilityLocal7
+)
+
+// Default syslog settings.
+const (
+	// DefaultSyslogStructuredDataID is the SD-ID under which fields are sent in RFC 5424 messages.
+	// 32473 is the private enterprise number reserved for documentation.
+	DefaultSyslogStructuredDataID = "fields@32473"
+	// DefaultSyslogDialTimeout is the timeout for establishing connections.
+	DefaultSyslogDialTimeout = 5 * time.Second
+)
+
+// syslogLocalPaths are tried in order when no network is configured.
+var syslogLocalPaths = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// SyslogConfig configures a SyslogWriter.
+type SyslogConfig struct {
+	// Network is "udp", "tcp", "unix" or "unixgram".
+	// When empty, the local syslog socket (/dev/log) is used.
+	Network string
+	// Address is the remote address or socket path.
+	Address string
+
+	// Format is the message format. RFC5424 is used by default.
+	Format SyslogFormat
+	// Facility is the syslog facility. FacilityKern (the zero value)
+	// is reserved for the kernel and is replaced by FacilityUser.
+	Facility SyslogFacility
+
+	// AppName identifies the application. The executable name is used when empty.
+	AppName string
+	// MsgID is the RFC 5424 MSGID. "-" is used when empty.
+	MsgID string
+	// Hostname is reported in the message. os.Hostname is used when empty.
+	Hostname string
+	// StructuredDataID is the SD-ID for fields. DefaultSyslogStructuredDataID is used when empty.
+	StructuredDataID string
+
+	// DialTimeout limits connection setup. DefaultSyslogDialTimeout is used when zero.
+	DialTimeout time.Duration
+	// WriteTimeout sets a deadline for every write. No deadline is set when zero.
+	WriteTimeout time.Duration
+}
+
+// SyslogWriter sends records to a syslog server.
+//
+// Log levels are mapped with LogLevel.SyslogSeverity. In RFC 5424 format,
+// fields are sent as structured data parameters. Messages are framed with
+// octet counting over TCP, terminated with a newline over unix stream sockets
+// and sent one per datagram over UDP and unixgram.
+// A failed write reconnects once and retries.
+type SyslogWriter struct {
+	mu      sync.Mutex
+	cfg     SyslogConfig
+	network string
+	address string
+	conn    net.Conn
+	pid     string
+}
+
+// NewSyslogWriter creates a new SyslogWriter and connects to the configured address.
+func NewSyslogWriter(cfg *SyslogConfig) (*SyslogWriter, error) {
+	c := SyslogConfig{}
+	if cfg != nil {
+		c = *cfg
+	}
+
+	if c.Facility == FacilityKern {
+		c.Facility = FacilityUser
+	}
+	if c.AppName == "" {
+		c.AppName = filepath.Base(os.Args[0])
+	}
+	if c.Hostname == "" {
+		c.Hostname, _ = os.Hostname()
+	}
+	if c.StructuredDataID == "" {
+		c.StructuredDataID = DefaultSyslogStructuredDataID
+	}
+	if c.DialTimeout <= 0 {
+		c.DialTimeout = DefaultSyslogDialTimeout
+	}
+
+	w := &SyslogWriter{
+		cfg:     c,
+		network: c.Network,
+		address: c.Address,
+		pid:     strconv.Itoa(os.Getpid()),
+	}
+
+	if err := w.connect(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Write sends bytes as an INFO level syslog message.
+func (w *SyslogWriter) Write(bytes []byte) (int, error) {
+	record := &Record{Time: time.Now(), Level: InfoLevel, Message: string(bytes)}
+	if err := w.WriteRecord(record, bytes); err != nil {
+		return 0, err
+	}
+	return len(bytes), nil
+}
+
+// WriteRecord sends the record as a syslog message.
+func (w *SyslogWriter) WriteRecord(record *Record, line []byte) error {
+	var msg string
+	if w.cfg.Format == RFC3164 {
+		msg = w.formatRFC3164(record)
+	} else {
+		msg = w.formatRFC5424(record)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn == nil {
+		return os.ErrClosed
+	}
+
+	frame := w.frame(msg)
+	if err := w.send(frame); err == nil {
+		return nil
+	}
+
+	// The syslog daemon may have been restarted, reconnect once.
+	_ = w.conn.Close()
+	if err := w.connect(); err != nil {
+		return err
+	}
+	return w.send(frame)
+}
+
+func (w *SyslogWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn == nil {
+		return os.ErrClosed
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+func (w *SyslogWriter) connect() error {
+	if w.network != "" {
+		conn, err := net.DialTimeout(w.network, w.address, w.cfg.DialTimeout)
+		if err != nil {
+			return err
+		}
+		w.conn = conn
+		return nil
+	}
+
+	paths := syslogLocalPaths
+	if w.address != "" {
+		paths = []string{w.address}
+	}
+
+	var errs []error
+	for _, path := range paths {
+		for _, network := range []string{"unixgram", "unix"} {
+			conn, err := net.DialTimeout(network, path, w.cfg.DialTimeout)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			w.conn = conn
+			w.network = network
+			w.address = path
+			return nil
+		}
+	}
+	return fmt.Errorf("syslog: local syslog socket unavailable: %w", errors.Join(errs...))
+}
+
+func (w *SyslogWriter) send(frame []byte) error {
+	if w.cfg.WriteTimeout > 0 {
+		_ = w.conn.SetWriteDeadline(time.Now().Add(w.cfg.WriteTimeout))
+	}
+	_, err := w.conn.Write(frame)
+	return err
+}
+
+func (w *SyslogWriter) frame(msg string) []byte {
+	switch w.network {
+	case "tcp", "tcp4", "tcp6":
+		return []byte(strconv.Itoa(len(msg)) + " " + msg)
+	case "unix":
+		return []byte(msg + "\n")
+	default:
+		return []byte(msg)
+	}
+}
+
+func (w *SyslogWriter) priority(level LogLevel) int {
+	return int(w.cfg.Facility)*8 + level.SyslogSeverity()
+}
+
+// formatRFC5424 renders:
+//
+//	<PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID [SD-ID key="value"...] MSG
+func (w *SyslogWriter) formatRFC5424(record *Record) string {
+	var b strings.Builder
+
+	b.WriteString("<" + strconv.Itoa(w.priority(record.Level)) + ">1 ")
+	b.WriteString(record.Time.Format("2006-01-02T15:04:05.000000Z07:00"))
+	b.WriteByte(' ')
+	b.WriteString(syslogHeaderField(w.cfg.Hostname, 255))
+	b.WriteByte(' ')
+	b.WriteString(syslogHeaderField(w.cfg.AppName, 48))
+	b.WriteByte(' ')
+	b.WriteString(syslogHeaderField(w.pid, 128))
+	b.WriteByte(' ')
+	b.WriteString(syslogHeaderField(w.cfg.MsgID, 32))
+	b.WriteByte(' ')
+	b.WriteString(w.structuredData(record))
+
+	if record.Message != "" {
+		b.WriteByte(' ')
+		b.WriteString(record.Message)
+	}
+	return b.String()
+}
+
+// formatRFC3164 renders:
+//
+//	<PRI>Mmm dd hh:mm:ss HOSTNAME TAG[PID]: MSG key=value...
+func (w *SyslogWriter) formatRFC3164(record *Record) string {
+	var b strings.Builder
+
+	b.WriteString("<" + strconv.Itoa(w.priority(record.Level)) + ">")
+	b.WriteString(record.Time.Format(time.Stamp))
+	b.WriteByte(' ')
+	b.WriteString(syslogHeaderField(w.cfg.Hostname, 255))
+	b.WriteByte(' ')
+	b.WriteString(syslogHeaderField(w.cfg.AppName, 32))
+	b.WriteString("[" + w.pid + "]: ")
+	b.WriteString(record.Message)
+
+	if fields := (&LogfmtFormatter{}).Format(record.Fields); fields != "" {
+		b.WriteByte(' ')
+		b.WriteString(fields)
+	}
+	return b.String()
+}
+
+func (w *SyslogWriter) structuredData(record *Record) string {
+	if len(record.Fields) == 0 && record.Logger == "" && record.Caller == "" {
+		return "-"
+	}
+
+	var b strings.Builder
+	b.WriteString("[" + syslogSDName(w.cfg.StructuredDataID))
+
+	writeParam := func(name, value string) {
+		b.WriteString(" " + syslogSDName(name) + `="` + syslogSDValue(value) + `"`)
+	}
+	if record.Logger != "" {
+		writeParam("logger", record.Logger)
+	}
+	if record.Caller != "" {
+		writeParam("caller", record.Caller)
+	}
+	for _, k := range sortedKeys(record.Fields) {
+		writeParam(k, logfmtString(record.Fields[k]))
+	}
+
+	b.WriteByte(']')
+	return b.String()
+}
+
+// syslogHeaderField returns value restricted to printable ASCII without spaces,
+// truncated to max bytes, or "-" when empty.
+func syslogHeaderField(value string, max int) string {
+	value = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return '_'
+		}
+		return r
+	}, value)
+	if value == "" {
+		return "-"
+	}
+	if len(value) > max {
+		value = value[:max]
+	}
+	return value
+}
+
+// syslogSDName returns a valid SD-NAME: printable ASCII except '=', ' ', ']' and '"',
+// at most 32 characters. The '@' of an SD-ID is kept.
+func syslogSDName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 || r == '=' || r == ']' || r == '"' {
+			return '_'
+		}
+		return r
+	}, name)
+	if name == "" {
+		return "_"
+	}
+	if len(name) > 32 {
+		name = name[:32]
+	}
+	return name
+}
+
+// syslogSDValue escapes '"', '\' and ']' in a PARAM-VALUE.
+func syslogSDValue(value string) string {
+	var b strings.Builder
+	for _, r := range value {
+		if r == '"' || r == '\\' || r == ']' {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
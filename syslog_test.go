@@ -0,0 +1,196 @@
+package balogan
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSyslogWriter_RFC5424UDP(t *testing.T) {
+	listener := listenUDP(t)
+
+	w, err := NewSyslogWriter(&SyslogConfig{
+		Network:  "udp",
+		Address:  listener.LocalAddr().String(),
+		Facility: FacilityLocal0,
+		AppName:  "shop",
+		MsgID:    "ORDER",
+		Hostname: "web-1",
+	})
+	if err != nil {
+		t.Fatalf("NewSyslogWriter() error = %v", err)
+	}
+	defer w.Close()
+
+	var _ RecordWriter = w
+
+	logger := New(InfoLevel, w).WithFields(Fields{"order": 42, "note": `say "hi" [x]`})
+	logger.Error("Order failed")
+
+	msg := string(readDatagram(t, listener))
+
+	// local0 (16) * 8 + error (3) = 131
+	pattern := `^<131>1 \d{4}-\d\d-\d\dT\d\d:\d\d:\d\d\.\d{6}\S+ web-1 shop \d+ ORDER ` +
+		regexp.QuoteMeta(`[fields@32473 note="say \"hi\" [x\]" order="42"] Order failed`) + `$`
+	if !regexp.MustCompile(pattern).MatchString(msg) {
+		t.Errorf("Unexpected RFC 5424 message:\n%s", msg)
+	}
+}
+
+func TestSyslogWriter_RFC5424NoFields(t *testing.T) {
+	listener := listenUDP(t)
+
+	w, err := NewSyslogWriter(&SyslogConfig{Network: "udp", Address: listener.LocalAddr().String(), AppName: "app"})
+	if err != nil {
+		t.Fatalf("NewSyslogWriter() error = %v", err)
+	}
+	defer w.Close()
+
+	if _, err := w.Write([]byte("plain")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	msg := string(readDatagram(t, listener))
+	// user (1) * 8 + info (6) = 14
+	if !strings.HasPrefix(msg, "<14>1 ") || !strings.HasSuffix(msg, " app "+strconv.Itoa(os.Getpid())+" - - plain") {
+		t.Errorf("Unexpected RFC 5424 message:\n%s", msg)
+	}
+}
+
+func TestSyslogWriter_RFC3164(t *testing.T) {
+	listener := listenUDP(t)
+
+	w, err := NewSyslogWriter(&SyslogConfig{
+		Network:  "udp",
+		Address:  listener.LocalAddr().String(),
+		Format:   RFC3164,
+		Facility: FacilityDaemon,
+		AppName:  "worker",
+		Hostname: "host",
+	})
+	if err != nil {
+		t.Fatalf("NewSyslogWriter() error = %v", err)
+	}
+	defer w.Close()
+
+	record := &Record{
+		Time:    time.Date(2024, 3, 5, 7, 8, 9, 0, time.Local),
+		Level:   WarningLevel,
+		Message: "Queue is slow",
+		Fields:  Fields{"queue": "emails"},
+	}
+	if err := w.WriteRecord(record, nil); err != nil {
+		t.Fatalf("WriteRecord() error = %v", err)
+	}
+
+	// daemon (3) * 8 + warning (4) = 28
+	expected := "<28>Mar  5 07:08:09 host worker[" + strconv.Itoa(os.Getpid()) + "]: Queue is slow queue=emails"
+	if msg := string(readDatagram(t, listener)); msg != expected {
+		t.Errorf("Unexpected RFC 3164 message:\n%s\nwant\n%s", msg, expected)
+	}
+}
+
+func TestSyslogWriter_TCPOctetCounting(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	defer listener.Close()
+
+	messages := make(chan string, 2)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		for {
+			length, err := reader.ReadString(' ')
+			if err != nil {
+				return
+			}
+			n, err := strconv.Atoi(strings.TrimSpace(length))
+			if err != nil {
+				return
+			}
+			buf := make([]byte, n)
+			if _, err := io.ReadFull(reader, buf); err != nil {
+				return
+			}
+			messages <- string(buf)
+		}
+	}()
+
+	w, err := NewSyslogWriter(&SyslogConfig{Network: "tcp", Address: listener.Addr().String()})
+	if err != nil {
+		t.Fatalf("NewSyslogWriter() error = %v", err)
+	}
+	defer w.Close()
+
+	logger := New(InfoLevel, w)
+	logger.Info("first\nwith newline")
+	logger.Info("second")
+
+	for _, expected := range []string{"first\nwith newline", "second"} {
+		select {
+		case msg := <-messages:
+			if !strings.HasSuffix(msg, " "+expected) {
+				t.Errorf("Expected message ending with %q, got %q", expected, msg)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timed out waiting for syslog message")
+		}
+	}
+}
+
+func TestSyslogWriter_LocalSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.sock")
+	listener, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Skipf("unixgram sockets unavailable: %v", err)
+	}
+	defer listener.Close()
+
+	w, err := NewSyslogWriter(&SyslogConfig{Address: path})
+	if err != nil {
+		t.Fatalf("NewSyslogWriter() error = %v", err)
+	}
+	defer w.Close()
+
+	if _, err := w.Write([]byte("local message")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	buf := make([]byte, 4096)
+	_ = listener.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, err := listener.Read(buf)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if msg := string(buf[:n]); !strings.HasSuffix(msg, " local message") {
+		t.Errorf("Unexpected local syslog message: %q", msg)
+	}
+}
+
+func TestSyslogSDName(t *testing.T) {
+	tests := map[string]string{
+		"user_id":               "user_id",
+		"a=b c]d\"e":            "a_b_c_d_e",
+		"":                      "_",
+		strings.Repeat("x", 40): strings.Repeat("x", 32),
+		"ünï":                   "_n_",
+	}
+	for input, expected := range tests {
+		if got := syslogSDName(input); got != expected {
+			t.Errorf("syslogSDName(%q) = %q, want %q", input, got, expected)
+		}
+	}
+}
@@ -1,9 +1,15 @@
 package balogan
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
 )
 
 // LogWriter interface.
@@ -13,6 +19,143 @@ type LogWriter interface {
 	io.Closer
 }
 
+// RecordWriter is implemented by writers which need the structured record
+// in addition to the encoded line, for example to map fields onto a wire protocol.
+// The logger calls WriteRecord instead of Write for such writers.
+//
+// The record may be shared with other writers and must not be modified.
+type RecordWriter interface {
+	LogWriter
+	WriteRecord(record *Record, line []byte) error
+}
+
+// writeRecord delivers a record to w, using WriteRecord when w implements RecordWriter.
+// Lines which a LevelWriter encodes itself are rewritten by the multiline policy.
+func writeRecord(w LogWriter, record *Record, line []byte, policy MultilinePolicy) error {
+	switch rw := w.(type) {
+	case *LevelWriter:
+		return rw.writeRecord(record, line, policy)
+	case RecordWriter:
+		return rw.WriteRecord(record, line)
+	}
+
+	_, err := w.Write(line)
+	return err
+}
+
+// LevelWriter wraps a writer with its own minimum level and encoder,
+// so writers of one logger can receive different levels and formats.
+// Create it with NewLevelWriter or attach it with Logger.WithWriter.
+//
+// The logger's level is checked first, so it must be at or below the lowest
+// level of its writers for that writer to receive anything.
+type LevelWriter struct {
+	writer   LogWriter
+	minLevel LogLevel
+	encoder  Encoder
+}
+
+// NewLevelWriter creates a new LevelWriter.
+//
+// Parameters:
+//
+//	writer: The wrapped writer.
+//	minLevel: Records below this level are not passed to the writer.
+//	encoder: Encodes lines for the writer. The logger's line is passed on when nil.
+//
+// Example:
+//
+//	file, _ := NewFileLogWriter("debug.log")
+//	writer := NewLevelWriter(file, DebugLevel, &JSONEncoder{})
+func NewLevelWriter(writer LogWriter, minLevel LogLevel, encoder Encoder) *LevelWriter {
+	return &LevelWriter{writer: writer, minLevel: minLevel, encoder: encoder}
+}
+
+// Write passes the message on as an INFO record, as it carries no level.
+func (w *LevelWriter) Write(bytes []byte) (int, error) {
+	record := &Record{Time: time.Now(), Level: InfoLevel, Message: string(bytes)}
+	if err := w.WriteRecord(record, bytes); err != nil {
+		return 0, err
+	}
+	return len(bytes), nil
+}
+
+// WriteRecord passes records at or above the minimum level on to the wrapped
+// writer, re-encoded when the LevelWriter has its own encoder.
+func (w *LevelWriter) WriteRecord(record *Record, line []byte) error {
+	return w.writeRecord(record, line, MultilineVerbatim)
+}
+
+// writeRecord is WriteRecord with the multiline policy of the logger,
+// which is applied to lines encoded by the writer's own encoder.
+func (w *LevelWriter) writeRecord(record *Record, line []byte, policy MultilinePolicy) error {
+	if !record.Level.IsEnabled(w.minLevel) {
+		return nil
+	}
+	if w.encoder != nil {
+		line = []byte(policy.apply(w.encoder.Encode(record)))
+	}
+	return writeRecord(w.writer, record, line, policy)
+}
+
+// Close closes the wrapped writer.
+func (w *LevelWriter) Close() error {
+	return w.writer.Close()
+}
+
+// WithWriter returns a new Logger instance which additionally writes to the
+// writer, filtered by its own minimum level and encoded by its own encoder.
+// Existing writers are unchanged.
+//
+// The logger's multiline policy applies to lines encoded by the writer's
+// encoder the same way as to the logger's own lines.
+//
+// Parameters:
+//
+//	writer: The writer to add.
+//	minLevel: Records below this level are not passed to the writer.
+//	encoder: Encodes lines for the writer. The logger's line is used when nil.
+//
+// Example:
+//
+//	file, _ := NewFileLogWriter("debug.log")
+//	logger := New(DebugLevel, NewLevelWriter(NewConsoleLogWriter(), InfoLevel, nil)).
+//		WithWriter(file, DebugLevel, &JSONEncoder{})
+//	logger.Debug("only in the file")
+//	logger.Info("on the console and in the file")
+func (l *Logger) WithWriter(writer LogWriter, minLevel LogLevel, encoder Encoder) *Logger {
+	writers := make([]LogWriter, len(l.writers), len(l.writers)+1)
+	copy(writers, l.writers)
+	writers = append(writers, NewLevelWriter(writer, minLevel, encoder))
+
+	return &Logger{
+		level:             l.level,
+		writers:           writers,
+		prefixes:          l.prefixes,
+		errorHandler:      l.errorHandler,
+		concurrency:       l.concurrency,
+		fields:            l.fields.Copy(),
+		fieldsFormatter:   l.fieldsFormatter,
+		conditions:        l.conditions,
+		levelConditions:   l.levelConditions,
+		contextConditions: l.contextConditions,
+		exitFunc:          l.exitFunc,
+		exitCode:          l.exitCode,
+		exitTimeout:       l.exitTimeout,
+		exitHandlers:      l.exitHandlers,
+		repanic:           l.repanic,
+		beforeHooks:       l.beforeHooks,
+		afterHooks:        l.afterHooks,
+		processors:        l.processors,
+		redactor:          l.redactor,
+		encoder:           l.encoder,
+		name:              l.name,
+		reportCaller:      l.reportCaller,
+		ctx:               l.ctx,
+		multiline:         l.multiline,
+	}
+}
+
 type StdOutLogWriter struct{}
 
 func (w *StdOutLogWriter) Write(bytes []byte) (int, error) {
@@ -29,42 +172,590 @@ func NewStdOutLogWriter() *StdOutLogWriter {
 	return &StdOutLogWriter{}
 }
 
+// SyncPolicy selects when a FileLogWriter flushes messages to disk.
+type SyncPolicy int
+
+const (
+	// SyncEveryWrite writes and fsyncs every message before Write returns.
+	// No message is lost on a crash, but throughput is bound by fsync latency.
+	SyncEveryWrite SyncPolicy = iota
+	// SyncInterval buffers messages and flushes and fsyncs them together
+	// every SyncInterval (group commit).
+	SyncInterval
+	// SyncBytes buffers messages and fsyncs once SyncBytes bytes were written
+	// since the last fsync.
+	SyncBytes
+	// SyncOS buffers messages and never fsyncs, leaving durability to the operating system.
+	SyncOS
+)
+
+// Default file writer settings.
+const (
+	// DefaultFileBufferSize is the size of the buffer of buffered sync policies.
+	DefaultFileBufferSize = 64 << 10
+	// DefaultFileSyncInterval is the flush interval of buffered sync policies.
+	DefaultFileSyncInterval = time.Second
+	// DefaultFileSyncBytes is the fsync threshold of SyncBytes.
+	DefaultFileSyncBytes = 1 << 20
+	// DefaultFileMode is the permission of new log files.
+	DefaultFileMode os.FileMode = 0644
+	// DefaultDirMode is the permission of directories created for log files.
+	DefaultDirMode os.FileMode = 0755
+)
+
+// FileConfig configures a FileLogWriter.
+type FileConfig struct {
+	// Filename is the path of the log file. It may be a template with strftime
+	// directives (see strftime) and {name} placeholders, for example
+	// "logs/%Y/%m/%d/{service}.log". Placeholders are replaced by the field of
+	// the same name of every record, which includes the logger's fields, and
+	// by PathFields when the record has no such field. When the expanded path
+	// changes, for example at midnight, the writer moves on to the new file.
+	Filename string
+	// PathFields are the default values of the {name} placeholders in Filename.
+	// Every placeholder needs a default, which is used for the first file and
+	// for messages written without a record.
+	PathFields map[string]string
+	// LocalTime expands strftime directives in local time instead of UTC.
+	LocalTime bool
+
+	// Mode is the permission of new log files, before the umask.
+	// DefaultFileMode is used when zero.
+	Mode os.FileMode
+	// CreateDirs creates missing parent directories with DirMode.
+	CreateDirs bool
+	// DirMode is the permission of created directories, before the umask.
+	// DefaultDirMode is used when zero.
+	DirMode os.FileMode
+
+	// Sync selects when messages are flushed to disk. SyncEveryWrite is used by default.
+	Sync SyncPolicy
+	// SyncInterval is the group commit interval of SyncInterval. With SyncBytes
+	// and SyncOS, the buffer is written to the file at this interval without fsync,
+	// so messages become visible to readers. DefaultFileSyncInterval is used when zero.
+	SyncInterval time.Duration
+	// SyncBytes is the fsync threshold of SyncBytes. DefaultFileSyncBytes is used when zero.
+	SyncBytes int64
+	// BufferSize is the size of the message buffer of the buffered policies.
+	// DefaultFileBufferSize is used when zero.
+	BufferSize int
+}
+
 // FileLogWriter writes log messages to a file.
+// Every message is terminated with a newline, so each record occupies one line.
+//
+// By default every message is synced to disk before Write returns. The other
+// sync policies of FileConfig buffer messages and trade durability for throughput.
+// Buffered messages are flushed on Close, which the logger calls before a fatal exit.
+//
+// Only one file is open at a time. Records which alternate between paths, for
+// example by a {service} placeholder, make the writer move between files, so
+// use one writer per path for heavy interleaving.
+//
+// The writer checks whether the file at its path is still the one it has open,
+// before every write or, with a buffered policy, before every flush. When the
+// file was moved or deleted, for example by logrotate, the path is reopened so
+// logging continues in the new file. Writes are serialized, so the writer is safe
+// for concurrent use.
 type FileLogWriter struct {
-	file *os.File
+	mu       sync.Mutex
+	cfg      FileConfig
+	pattern  string // Filename with PathFields replaced
+	fields   bool   // whether Filename has {name} placeholders
+	current  string // pattern of the open file
+	path     string // expanded path of the open file
+	checked  int64  // Unix second of the last path expansion
+	file     *os.File
+	info     os.FileInfo
+	closed   bool
+	buf      []byte
+	unsynced int64
+
+	// Pending error of background work, returned by the next write.
+	pendingErr error
+
+	signals chan os.Signal
+	stop    chan struct{}
+	wg      sync.WaitGroup
 }
 
-// NewFileLogWriter creates a new FileLogWriter.
+// NewFileLogWriter creates a new FileLogWriter which syncs every message to disk.
 func NewFileLogWriter(filename string) (*FileLogWriter, error) {
-	file, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	return NewFileLogWriterFromConfig(&FileConfig{Filename: filename})
+}
+
+// NewFileLogWriterFromConfig creates a new FileLogWriter from a FileConfig.
+//
+// Parameters:
+//
+//	cfg: The file writer configuration.
+//
+// Example:
+//
+//	writer, err := NewFileLogWriterFromConfig(&FileConfig{
+//		Filename:     "/var/log/app.log",
+//		Sync:         SyncInterval,
+//		SyncInterval: 100 * time.Millisecond,
+//	})
+func NewFileLogWriterFromConfig(cfg *FileConfig) (*FileLogWriter, error) {
+	if cfg == nil || cfg.Filename == "" {
+		return nil, errors.New("file writer: filename is required")
+	}
+
+	c := *cfg
+	if c.Sync < SyncEveryWrite || c.Sync > SyncOS {
+		return nil, fmt.Errorf("file writer: unsupported sync policy %d", c.Sync)
+	}
+	if c.SyncInterval <= 0 {
+		c.SyncInterval = DefaultFileSyncInterval
+	}
+	if c.SyncBytes <= 0 {
+		c.SyncBytes = DefaultFileSyncBytes
+	}
+	if c.BufferSize <= 0 {
+		c.BufferSize = DefaultFileBufferSize
+	}
+	if c.Mode == 0 {
+		c.Mode = DefaultFileMode
+	}
+	if c.DirMode == 0 {
+		c.DirMode = DefaultDirMode
+	}
+
+	pattern, err := expandPathFields(c.Filename, func(name string) (string, bool) {
+		value, ok := c.PathFields[name]
+		return value, ok
+	})
 	if err != nil {
 		return nil, err
 	}
-	return &FileLogWriter{file: file}, nil
+
+	w := &FileLogWriter{
+		cfg:     c,
+		pattern: pattern,
+		fields:  strings.Contains(c.Filename, "{"),
+		current: pattern,
+		stop:    make(chan struct{}),
+	}
+	now := time.Now()
+	w.path = w.expandPath(pattern, now)
+	w.checked = now.Unix()
+	if w.file, w.info, err = w.open(w.path); err != nil {
+		return nil, err
+	}
+
+	if w.buffered() {
+		w.buf = make([]byte, 0, c.BufferSize)
+		w.wg.Add(1)
+		go w.runFlusher()
+	}
+	return w, nil
+}
+
+// expandPathFields replaces the {name} placeholders of a path template with
+// the values returned by lookup. '%' in values is escaped, so values are never
+// read as strftime directives.
+func expandPathFields(pattern string, lookup func(name string) (string, bool)) (string, error) {
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(pattern, '{')
+		if start < 0 {
+			b.WriteString(pattern)
+			return b.String(), nil
+		}
+		end := strings.IndexByte(pattern[start:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("file writer: unterminated placeholder in %q", pattern)
+		}
+
+		name := pattern[start+1 : start+end]
+		value, ok := lookup(name)
+		if !ok {
+			return "", fmt.Errorf("file writer: no path field %q", name)
+		}
+		b.WriteString(pattern[:start])
+		b.WriteString(strings.ReplaceAll(value, "%", "%%"))
+		pattern = pattern[start+end+1:]
+	}
 }
 
+// recordPattern returns the path template with the placeholders replaced by
+// the record's fields, falling back to PathFields. Path separators in field
+// values are replaced, so a record cannot write outside of the template's directory.
+func (w *FileLogWriter) recordPattern(record *Record) string {
+	pattern, err := expandPathFields(w.cfg.Filename, func(name string) (string, bool) {
+		if value, ok := record.Fields[name]; ok {
+			return pathFieldValue(value), true
+		}
+		value, ok := w.cfg.PathFields[name]
+		return value, ok
+	})
+	if err != nil {
+		// The template was validated with PathFields in NewFileLogWriterFromConfig.
+		return w.pattern
+	}
+	return pattern
+}
+
+// pathFieldValue formats a field value for use as a single path element.
+func pathFieldValue(value interface{}) string {
+	s := strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == 0 {
+			return '_'
+		}
+		return r
+	}, fmt.Sprint(value))
+	if s == "" || s == "." || s == ".." {
+		return "_"
+	}
+	return s
+}
+
+// expandPath returns the path of the log file for pattern at time t.
+func (w *FileLogWriter) expandPath(pattern string, t time.Time) string {
+	if !w.cfg.LocalTime {
+		t = t.UTC()
+	}
+	return strftime(pattern, t)
+}
+
+// open opens the log file at path, creating its directory when configured.
+func (w *FileLogWriter) open(path string) (*os.File, os.FileInfo, error) {
+	if w.cfg.CreateDirs {
+		if err := os.MkdirAll(filepath.Dir(path), w.cfg.DirMode); err != nil {
+			return nil, nil, err
+		}
+	}
+	return openLogFile(path, w.cfg.Mode)
+}
+
+func openLogFile(filename string, mode os.FileMode) (*os.File, os.FileInfo, error) {
+	file, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, mode)
+	if err != nil {
+		return nil, nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+	return file, info, nil
+}
+
+// Write writes the message to the file at the path expanded with PathFields.
 func (w *FileLogWriter) Write(bytes []byte) (int, error) {
+	return w.write(w.pattern, bytes)
+}
+
+// WriteRecord writes the line to the file at the path expanded with the record's fields.
+func (w *FileLogWriter) WriteRecord(record *Record, line []byte) error {
+	pattern := w.pattern
+	if w.fields {
+		pattern = w.recordPattern(record)
+	}
+	_, err := w.write(pattern, line)
+	return err
+}
+
+func (w *FileLogWriter) write(pattern string, bytes []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
 	if w.file == nil {
 		return 0, os.ErrNotExist
 	}
+	if w.closed {
+		return 0, os.ErrClosed
+	}
 
-	count, err := w.file.Write(bytes)
-	if err != nil {
+	line := bytes
+	if len(line) == 0 || line[len(line)-1] != '\n' {
+		line = append(line[:len(line):len(line)], '\n')
+	}
+
+	pendingErr := w.pendingErr
+	w.pendingErr = nil
+
+	// A failed roll is reported, but the message still goes to the current file.
+	pendingErr = errors.Join(pendingErr, w.rollIfNeeded(pattern))
+
+	if w.buffered() {
+		// A failed reopen is reported, but messages still go to the old file.
+		if len(w.buf)+len(line) > cap(w.buf) && len(w.buf) > 0 {
+			pendingErr = errors.Join(pendingErr, w.reopenIfMoved())
+			if err := w.flush(false); err != nil {
+				return 0, errors.Join(pendingErr, err)
+			}
+		}
+		w.buf = append(w.buf, line...)
+		w.unsynced += int64(len(line))
+
+		if w.cfg.Sync == SyncBytes && w.unsynced >= w.cfg.SyncBytes {
+			pendingErr = errors.Join(pendingErr, w.reopenIfMoved())
+			if err := w.flush(true); err != nil {
+				return 0, errors.Join(pendingErr, err)
+			}
+		}
+		return len(bytes), pendingErr
+	}
+
+	// A failed reopen is reported, but the message still goes to the old file.
+	pendingErr = errors.Join(pendingErr, w.reopenIfMoved())
+
+	if _, err := w.file.Write(line); err != nil {
 		return 0, err
 	}
 
-	err = w.file.Sync()
+	err := w.file.Sync()
 	if err != nil {
 		return 0, err
 	}
 
-	return count, nil
+	return len(bytes), pendingErr
+}
+
+// Flush writes buffered messages to the file and syncs it to disk,
+// regardless of the sync policy.
+func (w *FileLogWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return os.ErrNotExist
+	}
+	if w.closed {
+		return os.ErrClosed
+	}
+	reopenErr := w.reopenIfMoved()
+	if err := w.flush(false); err != nil {
+		return errors.Join(reopenErr, err)
+	}
+	return errors.Join(reopenErr, w.sync())
+}
+
+// Reopen closes the log file and opens its path again.
+//
+// Call Reopen after the file was moved by an external tool such as logrotate.
+// The new file is opened before the old one is closed and no message is written
+// in between, so no lines are lost or interleaved.
+//
+// Example:
+//
+//	writer, _ := NewFileLogWriter("/var/log/app.log")
+//	// in a logrotate postrotate script: kill -HUP <pid>
+//	_ = writer.ReopenOnSignal()
+func (w *FileLogWriter) Reopen() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return os.ErrNotExist
+	}
+	if w.closed {
+		return os.ErrClosed
+	}
+	return w.reopen()
+}
+
+// ReopenOnSignal reopens the log file whenever one of the signals is received,
+// until the writer is closed. SIGHUP is used when no signal is given.
+// Errors of the reopen are returned by the next Write.
+//
+// Parameters:
+//
+//	signals: The signals to listen for. Defaults to SIGHUP.
+//
+// Example:
+//
+//	writer, _ := NewFileLogWriter("/var/log/app.log")
+//	if err := writer.ReopenOnSignal(); err != nil {
+//		// signals are not supported on this platform
+//	}
+func (w *FileLogWriter) ReopenOnSignal(signals ...os.Signal) error {
+	if len(signals) == 0 {
+		signals = reopenSignals
+	}
+	if len(signals) == 0 {
+		return errors.New("file writer: no reopen signal on this platform")
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return os.ErrNotExist
+	}
+	if w.closed {
+		return os.ErrClosed
+	}
+	if w.signals != nil {
+		return errors.New("file writer: already listening for signals")
+	}
+
+	w.signals = make(chan os.Signal, 1)
+	signal.Notify(w.signals, signals...)
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		for {
+			select {
+			case <-w.signals:
+				if err := w.Reopen(); err != nil && !errors.Is(err, os.ErrClosed) {
+					w.setPendingErr(err)
+				}
+			case <-w.stop:
+				return
+			}
+		}
+	}()
+	return nil
 }
 
+// Close flushes buffered messages, syncs and closes the file.
 func (w *FileLogWriter) Close() error {
+	w.mu.Lock()
 	if w.file == nil {
+		w.mu.Unlock()
 		return os.ErrNotExist
 	}
+	if w.closed {
+		w.mu.Unlock()
+		return os.ErrClosed
+	}
+	w.closed = true
+	err := w.flush(true)
+	err = errors.Join(err, w.file.Close())
+	w.mu.Unlock()
+
+	if w.signals != nil {
+		signal.Stop(w.signals)
+	}
+	close(w.stop)
+	w.wg.Wait()
+	return err
+}
+
+func (w *FileLogWriter) buffered() bool {
+	return w.cfg.Sync != SyncEveryWrite
+}
+
+// runFlusher flushes the buffer every SyncInterval until the writer is closed.
+func (w *FileLogWriter) runFlusher() {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.cfg.SyncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			w.mu.Lock()
+			if !w.closed && (len(w.buf) > 0 || w.cfg.Sync == SyncInterval && w.unsynced > 0) {
+				err := errors.Join(w.reopenIfMoved(), w.flush(w.cfg.Sync == SyncInterval))
+				w.pendingErr = errors.Join(w.pendingErr, err)
+			}
+			w.mu.Unlock()
+		case <-w.stop:
+			return
+		}
+	}
+}
 
-	return w.file.Close()
+// flush writes the buffer to the file and syncs it when sync is set and the
+// policy allows it. The caller must hold w.mu.
+func (w *FileLogWriter) flush(sync bool) error {
+	if len(w.buf) > 0 {
+		_, err := w.file.Write(w.buf)
+		w.buf = w.buf[:0]
+		if err != nil {
+			return err
+		}
+	}
+	if sync && w.cfg.Sync != SyncOS && w.unsynced > 0 {
+		return w.sync()
+	}
+	return nil
+}
+
+// rollIfNeeded moves on to a new file when the expanded path of pattern
+// differs from the open file. Buffered messages are written to the old file
+// first. For an unchanged pattern, the path is expanded at most once per second.
+// The caller must hold w.mu.
+func (w *FileLogWriter) rollIfNeeded(pattern string) error {
+	now := time.Now()
+	if pattern == w.current && (!strings.Contains(pattern, "%") || now.Unix() == w.checked) {
+		return nil
+	}
+	w.current = pattern
+	w.checked = now.Unix()
+
+	path := w.expandPath(pattern, now)
+	if path == w.path {
+		return nil
+	}
+
+	flushErr := w.flush(true)
+	file, info, err := w.open(path)
+	if err != nil {
+		// Try again with the next message.
+		w.current = ""
+		return errors.Join(flushErr, err)
+	}
+
+	old := w.file
+	w.path = path
+	w.file = file
+	w.info = info
+	w.unsynced = 0
+	return errors.Join(flushErr, old.Close())
+}
+
+// reopenIfMoved reopens the path when the file was moved or deleted.
+// The caller must hold w.mu.
+func (w *FileLogWriter) reopenIfMoved() error {
+	if w.moved() {
+		return w.reopen()
+	}
+	return nil
+}
+
+// sync syncs the file to disk. The caller must hold w.mu.
+func (w *FileLogWriter) sync() error {
+	w.unsynced = 0
+	return w.file.Sync()
+}
+
+func (w *FileLogWriter) setPendingErr(err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.pendingErr = errors.Join(w.pendingErr, err)
+}
+
+// moved reports whether the path no longer refers to the open file.
+// The caller must hold w.mu.
+func (w *FileLogWriter) moved() bool {
+	info, err := os.Stat(w.path)
+	if err != nil {
+		return true
+	}
+	return !os.SameFile(info, w.info)
+}
+
+// reopen swaps the open file for a new one at the same path. Buffered messages
+// are written to the new file. The caller must hold w.mu.
+func (w *FileLogWriter) reopen() error {
+	file, info, err := w.open(w.path)
+	if err != nil {
+		return err
+	}
+
+	old := w.file
+	w.file = file
+	w.info = info
+	if w.unsynced > 0 && w.cfg.Sync != SyncOS {
+		// Messages flushed to the old file since the last sync.
+		_ = old.Sync()
+	}
+	return old.Close()
 }
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package balogan
+
+import "os"
+
+// reopenSignals is empty on platforms without SIGHUP.
+var reopenSignals []os.Signal
@@ -2,9 +2,13 @@ package balogan
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestStdOutLogWriter_WriteAndClose(t *testing.T) {
@@ -129,3 +133,462 @@ func TestFileLogWriter_MultipleWrites(t *testing.T) {
 		}
 	}
 }
+
+func TestFileLogWriter_TerminatesLines(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "app.log")
+	w, err := NewFileLogWriter(filename)
+	if err != nil {
+		t.Fatalf("NewFileLogWriter() error = %v", err)
+	}
+
+	logger := New(InfoLevel, w)
+	logger.WithField("k", "v").Info("key value")
+	logger.WithJSON().WithField("k", "v").Info("json fields")
+	logger.WithEncoder(&JSONEncoder{}).Info("json record")
+
+	// Messages which already end with a newline are written unchanged.
+	msg := []byte("terminated\n")
+	if n, err := w.Write(msg); err != nil || n != len(msg) {
+		t.Errorf("FileLogWriter.Write() = %d, %v, want %d, nil", n, err, len(msg))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("FileLogWriter.Close() error = %v", err)
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("os.ReadFile() error = %v", err)
+	}
+	lines := strings.Split(string(data), "\n")
+	if len(lines) != 5 || lines[4] != "" {
+		t.Fatalf("Expected 4 newline terminated lines, got %q", data)
+	}
+	if lines[0] != "INFO k=v key value" {
+		t.Errorf("Unexpected key=value line %q", lines[0])
+	}
+	if lines[1] != `INFO {"k":"v"} json fields` {
+		t.Errorf("Unexpected JSON fields line %q", lines[1])
+	}
+	if !json.Valid([]byte(lines[2])) {
+		t.Errorf("Expected one JSON record per line, got %q", lines[2])
+	}
+	if lines[3] != "terminated" {
+		t.Errorf("Expected no extra newline after a terminated message, got %q", data)
+	}
+}
+
+func TestFileLogWriter_Reopen(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+
+	w, err := NewFileLogWriter(filename)
+	if err != nil {
+		t.Fatalf("NewFileLogWriter() error = %v", err)
+	}
+	defer w.Close()
+
+	_, _ = w.Write([]byte("before rotation"))
+	if err := os.Rename(filename, filename+".1"); err != nil {
+		t.Fatalf("os.Rename() error = %v", err)
+	}
+	if err := w.Reopen(); err != nil {
+		t.Fatalf("FileLogWriter.Reopen() error = %v", err)
+	}
+	_, _ = w.Write([]byte("after rotation"))
+
+	rotated, _ := os.ReadFile(filename + ".1")
+	if string(rotated) != "before rotation\n" {
+		t.Errorf("Rotated file = %q, want only the line before rotation", rotated)
+	}
+	current, _ := os.ReadFile(filename)
+	if string(current) != "after rotation\n" {
+		t.Errorf("New file = %q, want only the line after rotation", current)
+	}
+}
+
+func TestFileLogWriter_DetectsMovedFile(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+
+	w, err := NewFileLogWriter(filename)
+	if err != nil {
+		t.Fatalf("NewFileLogWriter() error = %v", err)
+	}
+	defer w.Close()
+
+	_, _ = w.Write([]byte("first"))
+	if err := os.Rename(filename, filename+".1"); err != nil {
+		t.Fatalf("os.Rename() error = %v", err)
+	}
+	if _, err := w.Write([]byte("second")); err != nil {
+		t.Fatalf("FileLogWriter.Write() error = %v", err)
+	}
+
+	current, _ := os.ReadFile(filename)
+	if string(current) != "second\n" {
+		t.Errorf("New file = %q, want the line written after the move", current)
+	}
+
+	if err := os.Remove(filename); err != nil {
+		t.Fatalf("os.Remove() error = %v", err)
+	}
+	if _, err := w.Write([]byte("third")); err != nil {
+		t.Fatalf("FileLogWriter.Write() error = %v", err)
+	}
+	current, _ = os.ReadFile(filename)
+	if string(current) != "third\n" {
+		t.Errorf("Recreated file = %q, want the line written after the delete", current)
+	}
+}
+
+func TestFileLogWriter_ReopenAfterClose(t *testing.T) {
+	w, err := NewFileLogWriter(filepath.Join(t.TempDir(), "app.log"))
+	if err != nil {
+		t.Fatalf("NewFileLogWriter() error = %v", err)
+	}
+	_ = w.Close()
+
+	if err := w.Reopen(); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("FileLogWriter.Reopen() error = %v, want os.ErrClosed", err)
+	}
+	if err := w.ReopenOnSignal(os.Interrupt); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("FileLogWriter.ReopenOnSignal() error = %v, want os.ErrClosed", err)
+	}
+}
+
+func newBufferedFileLogWriter(t *testing.T, cfg FileConfig) (*FileLogWriter, string) {
+	t.Helper()
+	cfg.Filename = filepath.Join(t.TempDir(), "app.log")
+	w, err := NewFileLogWriterFromConfig(&cfg)
+	if err != nil {
+		t.Fatalf("NewFileLogWriterFromConfig() error = %v", err)
+	}
+	return w, cfg.Filename
+}
+
+func readFile(t *testing.T, filename string) string {
+	t.Helper()
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("os.ReadFile() error = %v", err)
+	}
+	return string(data)
+}
+
+func TestFileLogWriter_SyncInterval(t *testing.T) {
+	w, filename := newBufferedFileLogWriter(t, FileConfig{Sync: SyncInterval, SyncInterval: 20 * time.Millisecond})
+	defer w.Close()
+
+	if _, err := w.Write([]byte("buffered")); err != nil {
+		t.Fatalf("FileLogWriter.Write() error = %v", err)
+	}
+	if got := readFile(t, filename); got != "" {
+		t.Errorf("File = %q, want messages to be buffered until the interval", got)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for readFile(t, filename) != "buffered\n" {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the buffer to be flushed after the interval")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestFileLogWriter_SyncBytes(t *testing.T) {
+	w, filename := newBufferedFileLogWriter(t, FileConfig{Sync: SyncBytes, SyncBytes: 10, SyncInterval: time.Hour})
+	defer w.Close()
+
+	_, _ = w.Write([]byte("short"))
+	if got := readFile(t, filename); got != "" {
+		t.Errorf("File = %q, want messages below the threshold to be buffered", got)
+	}
+	_, _ = w.Write([]byte("threshold"))
+	if got := readFile(t, filename); got != "short\nthreshold\n" {
+		t.Errorf("File = %q, want the buffer to be flushed at the threshold", got)
+	}
+}
+
+func TestFileLogWriter_BufferFull(t *testing.T) {
+	w, filename := newBufferedFileLogWriter(t, FileConfig{Sync: SyncOS, BufferSize: 8, SyncInterval: time.Hour})
+	defer w.Close()
+
+	_, _ = w.Write([]byte("first"))
+	_, _ = w.Write([]byte("second"))
+	if got := readFile(t, filename); got != "first\n" {
+		t.Errorf("File = %q, want the full buffer to be written", got)
+	}
+
+	if err := w.Flush(); err != nil {
+		t.Fatalf("FileLogWriter.Flush() error = %v", err)
+	}
+	if got := readFile(t, filename); got != "first\nsecond\n" {
+		t.Errorf("File = %q, want Flush to write the buffer", got)
+	}
+}
+
+func TestFileLogWriter_CloseFlushes(t *testing.T) {
+	w, filename := newBufferedFileLogWriter(t, FileConfig{Sync: SyncInterval, SyncInterval: time.Hour})
+
+	logger := New(InfoLevel, w).WithExitFunc(func(int) {})
+	logger.Info("before exit")
+	logger.Fatal("fatal error")
+
+	if got := readFile(t, filename); got != "INFO before exit\nFATAL fatal error\n" {
+		t.Errorf("File = %q, want buffered messages to be flushed before a fatal exit", got)
+	}
+	if _, err := w.Write([]byte("late")); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("FileLogWriter.Write() error = %v, want os.ErrClosed", err)
+	}
+}
+
+func TestFileLogWriter_BufferedReopen(t *testing.T) {
+	w, filename := newBufferedFileLogWriter(t, FileConfig{Sync: SyncInterval, SyncInterval: time.Hour})
+	defer w.Close()
+
+	_, _ = w.Write([]byte("first"))
+	_ = w.Flush()
+	if err := os.Rename(filename, filename+".1"); err != nil {
+		t.Fatalf("os.Rename() error = %v", err)
+	}
+	_, _ = w.Write([]byte("second"))
+	if err := w.Flush(); err != nil {
+		t.Fatalf("FileLogWriter.Flush() error = %v", err)
+	}
+
+	if got := readFile(t, filename+".1"); got != "first\n" {
+		t.Errorf("Rotated file = %q, want the line flushed before the move", got)
+	}
+	if got := readFile(t, filename); got != "second\n" {
+		t.Errorf("New file = %q, want the line flushed after the move", got)
+	}
+}
+
+func TestNewFileLogWriterFromConfig_Invalid(t *testing.T) {
+	if _, err := NewFileLogWriterFromConfig(&FileConfig{}); err == nil {
+		t.Error("NewFileLogWriterFromConfig() should return error without filename")
+	}
+	if _, err := NewFileLogWriterFromConfig(&FileConfig{Filename: filepath.Join(t.TempDir(), "app.log"), Sync: SyncPolicy(42)}); err == nil {
+		t.Error("NewFileLogWriterFromConfig() should return error for unknown sync policy")
+	}
+}
+
+func TestFileLogWriter_PathTemplate(t *testing.T) {
+	dir := t.TempDir()
+	w, err := NewFileLogWriterFromConfig(&FileConfig{
+		Filename:   filepath.Join(dir, "logs", "%Y", "{service}.log"),
+		PathFields: map[string]string{"service": "api-100%"},
+		CreateDirs: true,
+	})
+	if err != nil {
+		t.Fatalf("NewFileLogWriterFromConfig() error = %v", err)
+	}
+	_, _ = w.Write([]byte("templated"))
+	_ = w.Close()
+
+	year := time.Now().UTC().Format("2006")
+	if got := readFile(t, filepath.Join(dir, "logs", year, "api-100%.log")); got != "templated\n" {
+		t.Errorf("File = %q, want the message in the expanded path", got)
+	}
+}
+
+func TestFileLogWriter_PathTemplateFields(t *testing.T) {
+	dir := t.TempDir()
+	w, err := NewFileLogWriterFromConfig(&FileConfig{
+		Filename:   filepath.Join(dir, "{service}.log"),
+		PathFields: map[string]string{"service": "app"},
+	})
+	if err != nil {
+		t.Fatalf("NewFileLogWriterFromConfig() error = %v", err)
+	}
+
+	logger := New(InfoLevel, w)
+	logger.WithField("service", "billing").Info("from the logger's fields")
+	logger.Info("without the field")
+	logger.WithField("service", "../escape").Info("with a path separator")
+	if err := w.Close(); err != nil {
+		t.Fatalf("FileLogWriter.Close() error = %v", err)
+	}
+
+	expected := map[string]string{
+		"billing.log":   "INFO service=billing from the logger's fields\n",
+		"app.log":       "INFO without the field\n",
+		".._escape.log": "INFO service=../escape with a path separator\n",
+	}
+	for name, content := range expected {
+		if got := readFile(t, filepath.Join(dir, name)); got != content {
+			t.Errorf("%s = %q, want %q", name, got, content)
+		}
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != len(expected) {
+		t.Errorf("Expected %d files, got %d", len(expected), len(entries))
+	}
+}
+
+func TestFileLogWriter_RollsOnPathChange(t *testing.T) {
+	dir := t.TempDir()
+	w, err := NewFileLogWriterFromConfig(&FileConfig{
+		Filename: filepath.Join(dir, "%s.log"),
+		Sync:     SyncInterval,
+	})
+	if err != nil {
+		t.Fatalf("NewFileLogWriterFromConfig() error = %v", err)
+	}
+
+	_, _ = w.Write([]byte("first"))
+	second := time.Now().Unix()
+	for time.Now().Unix() == second {
+		time.Sleep(10 * time.Millisecond)
+	}
+	_, _ = w.Write([]byte("second"))
+	if err := w.Close(); err != nil {
+		t.Fatalf("FileLogWriter.Close() error = %v", err)
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 2 {
+		t.Fatalf("Expected a new file after the path changed, got %d files", len(entries))
+	}
+	if got := readFile(t, filepath.Join(dir, entries[0].Name())); got != "first\n" {
+		t.Errorf("First file = %q, want only the first message", got)
+	}
+	if got := readFile(t, filepath.Join(dir, entries[1].Name())); got != "second\n" {
+		t.Errorf("Second file = %q, want only the second message", got)
+	}
+}
+
+func TestFileLogWriter_CreateDirs(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "a", "b", "app.log")
+	if _, err := NewFileLogWriterFromConfig(&FileConfig{Filename: filename}); err == nil {
+		t.Error("NewFileLogWriterFromConfig() should fail for a missing directory without CreateDirs")
+	}
+
+	w, err := NewFileLogWriterFromConfig(&FileConfig{Filename: filename, CreateDirs: true})
+	if err != nil {
+		t.Fatalf("NewFileLogWriterFromConfig() error = %v", err)
+	}
+	defer w.Close()
+
+	// The directory is created again when it is deleted.
+	if err := os.RemoveAll(filepath.Dir(filename)); err != nil {
+		t.Fatalf("os.RemoveAll() error = %v", err)
+	}
+	if _, err := w.Write([]byte("recreated")); err != nil {
+		t.Fatalf("FileLogWriter.Write() error = %v", err)
+	}
+	if got := readFile(t, filename); got != "recreated\n" {
+		t.Errorf("File = %q, want the message in the recreated directory", got)
+	}
+}
+
+func TestNewFileLogWriterFromConfig_InvalidTemplate(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := NewFileLogWriterFromConfig(&FileConfig{Filename: filepath.Join(dir, "{service}.log")}); err == nil {
+		t.Error("NewFileLogWriterFromConfig() should return error for an unknown path field")
+	}
+	if _, err := NewFileLogWriterFromConfig(&FileConfig{Filename: filepath.Join(dir, "{service.log")}); err == nil {
+		t.Error("NewFileLogWriterFromConfig() should return error for an unterminated placeholder")
+	}
+}
+
+func TestLevelWriter(t *testing.T) {
+	inner := &lineWriter{}
+	w := NewLevelWriter(inner, WarningLevel, nil)
+	var _ RecordWriter = w
+
+	logger := New(DebugLevel, w)
+	logger.Info("dropped")
+	logger.Error("kept")
+
+	if len(inner.lines) != 1 || inner.lines[0] != "ERROR kept" {
+		t.Errorf("LevelWriter passed %q, want only records at or above WARNING", inner.lines)
+	}
+	if inner.records[0].Message != "kept" {
+		t.Errorf("LevelWriter should pass the record on, got %q", inner.records[0].Message)
+	}
+
+	if _, err := w.Write([]byte("plain")); err != nil {
+		t.Errorf("LevelWriter.Write() error = %v", err)
+	}
+	if len(inner.lines) != 1 {
+		t.Error("LevelWriter.Write() should treat messages without level as INFO")
+	}
+}
+
+func TestLogger_WithWriter(t *testing.T) {
+	console := &lineWriter{}
+	file := &MockWriter{}
+
+	base := New(DebugLevel, NewLevelWriter(console, InfoLevel, nil))
+	logger := base.WithWriter(file, DebugLevel, &JSONEncoder{}).WithField("user", "john")
+
+	logger.Debug("debugging")
+	logger.Info("logged in")
+
+	if strings.Join(console.lines, "|") != "INFO user=john logged in" {
+		t.Errorf("Console lines = %q, want only INFO in key=value", console.lines)
+	}
+	if strings.Count(file.String(), `"msg":`) != 2 || !strings.Contains(file.String(), `"msg":"debugging"`) || !strings.Contains(file.String(), `"user":"john"`) {
+		t.Errorf("File = %q, want DEBUG and INFO as JSON", file.String())
+	}
+
+	if len(base.writers) != 1 {
+		t.Error("WithWriter should not modify the original logger")
+	}
+	if err := logger.Close(); err != nil || !file.IsClosed() {
+		t.Errorf("Logger.Close() should close the added writer, error = %v", err)
+	}
+}
+
+func TestLogger_WithWriter_Multiline(t *testing.T) {
+	main := &lineWriter{}
+	console := &lineWriter{}
+	logger := New(InfoLevel, main).
+		WithWriter(console, InfoLevel, &TextEncoder{}).
+		WithMultiline(MultilineEscape)
+
+	logger.Info("query failed:\nSELECT 1")
+
+	expected := `INFO query failed:\nSELECT 1`
+	if len(main.lines) != 1 || main.lines[0] != expected {
+		t.Errorf("Main lines = %q, want %q", main.lines, expected)
+	}
+	if len(console.lines) != 1 || console.lines[0] != expected {
+		t.Errorf("Per-writer lines = %q, want the same escaped line %q", console.lines, expected)
+	}
+}
+
+func benchmarkFileLogWriter(b *testing.B, cfg FileConfig) {
+	cfg.Filename = filepath.Join(b.TempDir(), "bench.log")
+	w, err := NewFileLogWriterFromConfig(&cfg)
+	if err != nil {
+		b.Fatalf("NewFileLogWriterFromConfig() error = %v", err)
+	}
+	defer w.Close()
+
+	msg := []byte("INFO request handled method=GET path=/api/users status=200 duration=1.2ms")
+	b.SetBytes(int64(len(msg) + 1))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := w.Write(msg); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkFileLogWriter_SyncEveryWrite(b *testing.B) {
+	benchmarkFileLogWriter(b, FileConfig{Sync: SyncEveryWrite})
+}
+
+func BenchmarkFileLogWriter_SyncInterval(b *testing.B) {
+	benchmarkFileLogWriter(b, FileConfig{Sync: SyncInterval, SyncInterval: 100 * time.Millisecond})
+}
+
+func BenchmarkFileLogWriter_SyncBytes(b *testing.B) {
+	benchmarkFileLogWriter(b, FileConfig{Sync: SyncBytes, SyncBytes: 1 << 20})
+}
+
+func BenchmarkFileLogWriter_SyncOS(b *testing.B) {
+	benchmarkFileLogWriter(b, FileConfig{Sync: SyncOS})
+}
@@ -0,0 +1,11 @@
+//go:build unix
+
+package balogan
+
+import (
+	"os"
+	"syscall"
+)
+
+// reopenSignals are the default signals of FileLogWriter.ReopenOnSignal.
+var reopenSignals = []os.Signal{syscall.SIGHUP}
@@ -0,0 +1,86 @@
+//go:build unix
+
+package balogan
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestFileLogWriter_ReopenOnSignal(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+
+	w, err := NewFileLogWriter(filename)
+	if err != nil {
+		t.Fatalf("NewFileLogWriter() error = %v", err)
+	}
+	if err := w.ReopenOnSignal(); err != nil {
+		t.Fatalf("FileLogWriter.ReopenOnSignal() error = %v", err)
+	}
+	if err := w.ReopenOnSignal(); err == nil {
+		t.Error("FileLogWriter.ReopenOnSignal() should fail when already listening")
+	}
+
+	w.mu.Lock()
+	initial := w.file
+	w.mu.Unlock()
+
+	if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatalf("syscall.Kill() error = %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		w.mu.Lock()
+		reopened := w.file != initial
+		w.mu.Unlock()
+		if reopened {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the file to be reopened on SIGHUP")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if _, err := w.Write([]byte("after signal")); err != nil {
+		t.Fatalf("FileLogWriter.Write() error = %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("FileLogWriter.Close() error = %v", err)
+	}
+
+	data, _ := os.ReadFile(filename)
+	if string(data) != "after signal\n" {
+		t.Errorf("File = %q, want the line written after the signal", data)
+	}
+}
+
+func TestFileLogWriter_Mode(t *testing.T) {
+	old := syscall.Umask(0)
+	defer syscall.Umask(old)
+
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "logs", "app.log")
+	w, err := NewFileLogWriterFromConfig(&FileConfig{
+		Filename:   filename,
+		Mode:       0600,
+		CreateDirs: true,
+		DirMode:    0700,
+	})
+	if err != nil {
+		t.Fatalf("NewFileLogWriterFromConfig() error = %v", err)
+	}
+	defer w.Close()
+
+	if info, _ := os.Stat(filename); info.Mode().Perm() != 0600 {
+		t.Errorf("File mode = %v, want 0600", info.Mode().Perm())
+	}
+	if info, _ := os.Stat(filepath.Dir(filename)); info.Mode().Perm() != 0700 {
+		t.Errorf("Directory mode = %v, want 0700", info.Mode().Perm())
+	}
+}